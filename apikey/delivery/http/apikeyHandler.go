@@ -0,0 +1,89 @@
+package http
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"net/http"
+	"strconv"
+)
+
+// errInvalidID is returned for an :id path parameter that is not a number.
+var errInvalidID = domain.NewError(domain.CodeBadInput, "the id must be an integer")
+
+// IssuedKey is the answer to issuing or rotating a key. Secret is the only
+// copy of the key the server ever hands out.
+type IssuedKey struct {
+	domain.APIKey
+	Secret string `json:"secret"`
+}
+
+type APIKeyHandler struct {
+	APIKeyUseCase domain.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(e *echo.Echo, useCase domain.APIKeyUseCase) {
+	handler := &APIKeyHandler{
+		APIKeyUseCase: useCase,
+	}
+
+	e.GET("/admin/api-keys", handler.FetchAPIKeys)
+	e.POST("/admin/api-keys", handler.Issue)
+	e.POST("/admin/api-keys/:id/rotate", handler.Rotate)
+	e.DELETE("/admin/api-keys/:id", handler.Revoke)
+}
+
+func (h *APIKeyHandler) FetchAPIKeys(ec echo.Context) error {
+	keys, err := h.APIKeyUseCase.Fetch(ec.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Issue(ec echo.Context) error {
+	var key domain.APIKey
+	err := ec.Bind(&key)
+	if err != nil {
+		return err
+	}
+
+	if err = problem.NewValidator().Struct(&key); err != nil {
+		return err
+	}
+
+	secret, err := h.APIKeyUseCase.Issue(ec.Request().Context(), &key)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, IssuedKey{APIKey: key, Secret: secret})
+}
+
+func (h *APIKeyHandler) Rotate(ec echo.Context) error {
+	id, err := strconv.ParseInt(ec.Param("id"), 10, 64)
+	if err != nil {
+		return errInvalidID
+	}
+
+	key, secret, err := h.APIKeyUseCase.Rotate(ec.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, IssuedKey{APIKey: key, Secret: secret})
+}
+
+func (h *APIKeyHandler) Revoke(ec echo.Context) error {
+	id, err := strconv.ParseInt(ec.Param("id"), 10, 64)
+	if err != nil {
+		return errInvalidID
+	}
+
+	if err = h.APIKeyUseCase.Revoke(ec.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ec.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	keyHttp "github.com/angelRaynov/clean-architecture/apikey/delivery/http"
+	"github.com/angelRaynov/clean-architecture/apikey/repository/memory"
+	"github.com/angelRaynov/clean-architecture/apikey/usecase"
+	"github.com/angelRaynov/clean-architecture/auth"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyHandler_EndToEnd(t *testing.T) {
+	keys := usecase.NewAPIKeyUseCase(memory.NewAPIKeyRepository(), authMemory.NewAuthorRepository(), time.Second*2)
+
+	admin := domain.APIKey{Name: "bootstrap", Scopes: []string{domain.ScopeAdmin}}
+	ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "cli", Roles: []string{domain.RoleAdmin}})
+	adminSecret, err := keys.Issue(ctx, &admin)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(auth.APIKeyMiddleware(keys))
+	keyHttp.NewAPIKeyHandler(e, keys)
+
+	do := func(method, target, body, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if secret != "" {
+			req.Header.Set(auth.HeaderAPIKey, secret)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/admin/api-keys", `{"name":"importer","scopes":["write:articles"]}`, adminSecret)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var issued keyHttp.IssuedKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &issued))
+	assert.NotEmpty(t, issued.Secret)
+	assert.Equal(t, []string{domain.ScopeWriteArticles}, issued.Scopes)
+
+	rec = do(http.MethodPost, "/admin/api-keys", `{"scopes":["write:articles"]}`, adminSecret)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = do(http.MethodGet, "/admin/api-keys", "", adminSecret)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "hash")
+
+	var list []domain.APIKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 2)
+	assert.NotNil(t, list[0].LastUsedAt)
+
+	rec = do(http.MethodGet, "/admin/api-keys", "", issued.Secret)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = do(http.MethodGet, "/admin/api-keys", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = do(http.MethodPost, "/admin/api-keys/2/rotate", "", adminSecret)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var rotated keyHttp.IssuedKey
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rotated))
+	assert.NotEqual(t, issued.Secret, rotated.Secret)
+
+	rec = do(http.MethodGet, "/admin/api-keys", "", issued.Secret)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "the rotated secret no longer works")
+
+	rec = do(http.MethodDelete, "/admin/api-keys/2", "", adminSecret)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = do(http.MethodDelete, "/admin/api-keys/x", "", adminSecret)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(http.MethodDelete, "/admin/api-keys/42", "", adminSecret)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
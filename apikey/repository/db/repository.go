@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"strings"
+	"time"
+)
+
+const columns = `id, name, prefix, hash, scopes, author_id, created_at, last_used_at, revoked_at`
+
+type apiKeyRepo struct {
+	DB      *sql.DB
+	dialect dialect.Dialect
+}
+
+// NewAPIKeyRepository returns a repository for the api_key table of a MySQL,
+// PostgreSQL or SQLite database.
+func NewAPIKeyRepository(db *sql.DB, d dialect.Dialect) domain.APIKeyRepository {
+	return &apiKeyRepo{
+		DB:      db,
+		dialect: d,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan reads a row of columns. Scopes are stored space separated.
+func scan(row scanner) (domain.APIKey, error) {
+	var (
+		res        domain.APIKey
+		scopes     string
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+	)
+
+	err := row.Scan(
+		&res.ID,
+		&res.Name,
+		&res.Prefix,
+		&res.Hash,
+		&scopes,
+		&res.AuthorID,
+		&res.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	res.Scopes = strings.Fields(scopes)
+	if lastUsedAt.Valid {
+		res.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		res.RevokedAt = &revokedAt.Time
+	}
+
+	return res, nil
+}
+
+// nullTime turns a nil *time.Time into a NULL argument.
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return *t
+}
+
+func (r *apiKeyRepo) getOne(ctx context.Context, query string, args ...interface{}) (domain.APIKey, error) {
+	stmt, err := r.DB.PrepareContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	defer stmt.Close()
+
+	res, err := scan(stmt.QueryRowContext(ctx, r.dialect.Args(args...)...))
+	if err == sql.ErrNoRows {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	return res, err
+}
+
+func (r *apiKeyRepo) Fetch(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+columns+` FROM api_key ORDER BY id`)
+	if err != nil {
+		logging.FromContext(ctx).Error("query failed", "repository", "api_key", "err", err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logging.FromContext(ctx).Error("closing rows failed", "repository", "api_key", "err", errRow)
+		}
+	}()
+
+	result := make([]domain.APIKey, 0)
+	for rows.Next() {
+		k, err := scan(rows)
+		if err != nil {
+			logging.FromContext(ctx).Error("scanning row failed", "repository", "api_key", "err", err)
+			return nil, err
+		}
+
+		result = append(result, k)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *apiKeyRepo) GetByID(ctx context.Context, id int64) (domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+columns+` FROM api_key WHERE id = ?`, id)
+}
+
+func (r *apiKeyRepo) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+columns+` FROM api_key WHERE prefix = ?`, prefix)
+}
+
+func (r *apiKeyRepo) Store(ctx context.Context, k *domain.APIKey) error {
+	query := `INSERT INTO api_key (name, prefix, hash, scopes, author_id, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	if r.dialect.ReturningID() {
+		query += ` RETURNING id`
+	}
+
+	stmt, err := r.DB.PrepareContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	args := r.dialect.Args(k.Name, k.Prefix, k.Hash, strings.Join(k.Scopes, " "), k.AuthorID, k.CreatedAt)
+
+	if r.dialect.ReturningID() {
+		err = stmt.QueryRowContext(ctx, args...).Scan(&k.ID)
+		if r.dialect.IsUniqueViolation(err) {
+			return domain.ErrConflict
+		}
+
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if r.dialect.IsUniqueViolation(err) {
+		return domain.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	k.ID, err = res.LastInsertId()
+
+	return err
+}
+
+func (r *apiKeyRepo) Update(ctx context.Context, k *domain.APIKey) error {
+	query := `UPDATE api_key SET prefix = ?, hash = ?, revoked_at = ? WHERE id = ?`
+
+	err := r.exec(ctx, query, k.Prefix, k.Hash, nullTime(k.RevokedAt), k.ID)
+	if r.dialect.IsUniqueViolation(err) {
+		return domain.ErrConflict
+	}
+
+	return err
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	return r.exec(ctx, `UPDATE api_key SET last_used_at = ? WHERE id = ?`, at, id)
+}
+
+// exec runs a statement that must change exactly one row.
+func (r *apiKeyRepo) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := r.DB.PrepareContext(ctx, r.dialect.Rebind(query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, r.dialect.Args(args...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	if rowsAffected != 1 {
+		err = fmt.Errorf("err: rows affected %d", rowsAffected)
+	}
+
+	return err
+}
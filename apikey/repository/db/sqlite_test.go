@@ -0,0 +1,17 @@
+//go:build cgo
+
+package db
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"github.com/angelRaynov/clean-architecture/migration/migrationtest"
+	"testing"
+)
+
+func TestAPIKeyRepository_SQLiteContract(t *testing.T) {
+	repositorytest.APIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		return NewAPIKeyRepository(migrationtest.OpenSQLite(t), dialect.SQLite)
+	})
+}
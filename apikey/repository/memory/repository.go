@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// apiKeyRepo keeps API keys in a map guarded by a RWMutex.
+type apiKeyRepo struct {
+	mu     sync.RWMutex
+	keys   map[int64]domain.APIKey
+	lastID int64
+}
+
+func NewAPIKeyRepository() domain.APIKeyRepository {
+	return &apiKeyRepo{
+		keys: map[int64]domain.APIKey{},
+	}
+}
+
+// clone copies the slices and pointers of k so callers cannot change the
+// stored key through them.
+func clone(k domain.APIKey) domain.APIKey {
+	k.Scopes = append([]string(nil), k.Scopes...)
+	if k.LastUsedAt != nil {
+		t := *k.LastUsedAt
+		k.LastUsedAt = &t
+	}
+	if k.RevokedAt != nil {
+		t := *k.RevokedAt
+		k.RevokedAt = &t
+	}
+
+	return k
+}
+
+func (r *apiKeyRepo) Fetch(ctx context.Context) ([]domain.APIKey, error) {
+	r.mu.RLock()
+	res := make([]domain.APIKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		res = append(res, clone(k))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+func (r *apiKeyRepo) GetByID(ctx context.Context, id int64) (domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	return clone(k), nil
+}
+
+func (r *apiKeyRepo) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return clone(k), nil
+		}
+	}
+
+	return domain.APIKey{}, domain.ErrNotFound
+}
+
+// prefixTaken reports whether another key uses prefix. The caller holds mu.
+func (r *apiKeyRepo) prefixTaken(prefix string, id int64) bool {
+	for _, k := range r.keys {
+		if k.Prefix == prefix && k.ID != id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *apiKeyRepo) Store(ctx context.Context, k *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.prefixTaken(k.Prefix, 0) {
+		return domain.ErrConflict
+	}
+
+	r.lastID++
+	k.ID = r.lastID
+
+	stored := clone(*k)
+	stored.LastUsedAt = nil
+	stored.RevokedAt = nil
+	r.keys[k.ID] = stored
+
+	return nil
+}
+
+func (r *apiKeyRepo) Update(ctx context.Context, k *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[k.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	if r.prefixTaken(k.Prefix, k.ID) {
+		return domain.ErrConflict
+	}
+
+	updated := clone(*k)
+	existing.Prefix = updated.Prefix
+	existing.Hash = updated.Hash
+	existing.RevokedAt = updated.RevokedAt
+	r.keys[k.ID] = existing
+
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	existing.LastUsedAt = &at
+	r.keys[id] = existing
+
+	return nil
+}
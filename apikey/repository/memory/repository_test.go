@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"testing"
+)
+
+func TestAPIKeyRepository_Contract(t *testing.T) {
+	repositorytest.APIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		return NewAPIKeyRepository()
+	})
+}
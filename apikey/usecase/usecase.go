@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// keyLabel starts every key so leaked keys are easy to recognise.
+	keyLabel = "cak_"
+	// prefixBytes and secretBytes are the random bytes of the public prefix
+	// and of the secret part of a key.
+	prefixBytes = 6
+	secretBytes = 32
+	// lastUsedResolution bounds how often a busy key writes its last use.
+	lastUsedResolution = time.Minute
+)
+
+var errInvalidKey = domain.NewError(domain.CodeUnauthorized, "the API key is invalid")
+
+type apiKeyUseCase struct {
+	apiKeyRepo     domain.APIKeyRepository
+	authorRepo     domain.AuthorRepository
+	contextTimeout time.Duration
+}
+
+func NewAPIKeyUseCase(k domain.APIKeyRepository, ar domain.AuthorRepository, timeout time.Duration) domain.APIKeyUseCase {
+	return &apiKeyUseCase{
+		apiKeyRepo:     k,
+		authorRepo:     ar,
+		contextTimeout: timeout,
+	}
+}
+
+// requireAdmin lets only admins manage keys.
+func requireAdmin(ctx context.Context) error {
+	caller, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+
+	if !caller.IsAdmin() || !caller.Allows(domain.ScopeAdmin) {
+		return domain.NewError(domain.CodeForbidden, "managing API keys requires the admin role")
+	}
+
+	return nil
+}
+
+func (u apiKeyUseCase) Fetch(ctx context.Context) ([]domain.APIKey, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.apiKeyRepo.Fetch(ctx)
+}
+
+func (u apiKeyUseCase) Issue(ctx context.Context, key *domain.APIKey) (string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := u.validate(ctx, key); err != nil {
+		return "", err
+	}
+
+	secret, err := newSecret(key)
+	if err != nil {
+		return "", err
+	}
+
+	key.CreatedAt = time.Now().Truncate(time.Second)
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	if err = u.apiKeyRepo.Store(ctx, key); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// validate checks the name, scopes and author of a new key.
+func (u apiKeyUseCase) validate(ctx context.Context, key *domain.APIKey) error {
+	var fields []domain.FieldError
+	if strings.TrimSpace(key.Name) == "" {
+		fields = append(fields, domain.FieldError{Field: "name", Message: "is required"})
+	}
+
+	if len(key.Scopes) == 0 {
+		fields = append(fields, domain.FieldError{Field: "scopes", Message: "is required"})
+	}
+
+	for _, scope := range key.Scopes {
+		if !domain.ValidScope(scope) {
+			fields = append(fields, domain.FieldError{
+				Field:   "scopes",
+				Message: fmt.Sprintf("%q is not one of %s, %s or %s", scope, domain.ScopeReadArticles, domain.ScopeWriteArticles, domain.ScopeAdmin),
+			})
+		}
+	}
+
+	if key.AuthorID != 0 {
+		_, err := u.authorRepo.GetByID(ctx, key.AuthorID)
+		if errors.Is(err, domain.ErrNotFound) {
+			fields = append(fields, domain.FieldError{Field: "author_id", Message: "is not an existing author"})
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(fields) > 0 {
+		return domain.NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+func (u apiKeyUseCase) Rotate(ctx context.Context, id int64) (domain.APIKey, string, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	key, err := u.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	if key.Revoked() {
+		return domain.APIKey{}, "", domain.NewError(domain.CodeConflict, "a revoked key cannot be rotated")
+	}
+
+	secret, err := newSecret(&key)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	if err = u.apiKeyRepo.Update(ctx, &key); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (u apiKeyUseCase) Revoke(ctx context.Context, id int64) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	key, err := u.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if key.Revoked() {
+		return nil
+	}
+
+	now := time.Now().Truncate(time.Second)
+	key.RevokedAt = &now
+
+	return u.apiKeyRepo.Update(ctx, &key)
+}
+
+// Authenticate looks the key up by its prefix and compares hashes in
+// constant time. Unknown, malformed and revoked keys all fail the same way.
+func (u apiKeyUseCase) Authenticate(ctx context.Context, secret string) (domain.Identity, error) {
+	prefix, ok := parsePrefix(secret)
+	if !ok {
+		return domain.Identity{}, errInvalidKey
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	key, err := u.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Identity{}, errInvalidKey
+	}
+	if err != nil {
+		return domain.Identity{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hash(secret)), []byte(key.Hash)) != 1 || key.Revoked() {
+		return domain.Identity{}, errInvalidKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err = u.apiKeyRepo.TouchLastUsed(ctx, key.ID, now.Truncate(time.Second)); err != nil {
+			logging.FromContext(ctx).Warn("recording API key use failed", "api_key_id", key.ID, "err", err)
+		}
+	}
+
+	id := domain.Identity{
+		Subject:  "api-key:" + strconv.FormatInt(key.ID, 10),
+		AuthorID: key.AuthorID,
+		Scopes:   key.Scopes,
+	}
+	if key.HasScope(domain.ScopeAdmin) {
+		id.Roles = []string{domain.RoleAdmin}
+	}
+	if id.Scopes == nil {
+		id.Scopes = []string{}
+	}
+
+	return id, nil
+}
+
+// newSecret gives key a new random prefix and hash and returns the whole key,
+// which reads cak_<prefix>_<secret>.
+func newSecret(key *domain.APIKey) (string, error) {
+	raw := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	prefix := hex.EncodeToString(raw[:prefixBytes])
+	secret := keyLabel + prefix + "_" + base64.RawURLEncoding.EncodeToString(raw[prefixBytes:])
+
+	key.Prefix = prefix
+	key.Hash = hash(secret)
+
+	return secret, nil
+}
+
+func parsePrefix(secret string) (string, bool) {
+	rest := strings.TrimPrefix(secret, keyLabel)
+	if rest == secret || len(rest) <= prefixBytes*2+1 || rest[prefixBytes*2] != '_' {
+		return "", false
+	}
+
+	return rest[:prefixBytes*2], true
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
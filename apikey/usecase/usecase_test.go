@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/apikey/repository/memory"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func asAdmin() context.Context {
+	return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "admin", Roles: []string{domain.RoleAdmin}})
+}
+
+func TestAPIKeyUseCase_Lifecycle(t *testing.T) {
+	repo := memory.NewAPIKeyRepository()
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	mockAuthorRepo.On("GetByID", mock.Anything, int64(3)).Return(domain.Author{ID: 3}, nil).Once()
+	u := NewAPIKeyUseCase(repo, mockAuthorRepo, time.Second*2)
+
+	key := domain.APIKey{Name: "importer", Scopes: []string{domain.ScopeWriteArticles}, AuthorID: 3}
+	secret, err := u.Issue(asAdmin(), &key)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "cak_"+key.Prefix+"_"))
+	assert.NotContains(t, key.Hash, secret)
+
+	id, err := u.Authenticate(context.TODO(), secret)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), id.AuthorID)
+	assert.True(t, id.Allows(domain.ScopeWriteArticles))
+	assert.False(t, id.IsAdmin())
+
+	stored, err := repo.GetByID(context.TODO(), key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt, "the use is recorded")
+
+	rotated, newSecret, err := u.Rotate(asAdmin(), key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, rotated.ID)
+	assert.NotEqual(t, secret, newSecret)
+
+	_, err = u.Authenticate(context.TODO(), secret)
+	assert.Equal(t, errInvalidKey, err, "the old secret stops working")
+	_, err = u.Authenticate(context.TODO(), newSecret)
+	require.NoError(t, err)
+
+	require.NoError(t, u.Revoke(asAdmin(), key.ID))
+	_, err = u.Authenticate(context.TODO(), newSecret)
+	assert.Equal(t, errInvalidKey, err)
+
+	_, _, err = u.Rotate(asAdmin(), key.ID)
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+
+	mockAuthorRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyUseCase_AdminKeys(t *testing.T) {
+	u := NewAPIKeyUseCase(memory.NewAPIKeyRepository(), new(mocks.AuthorRepository), time.Second*2)
+
+	key := domain.APIKey{Name: "ops", Scopes: []string{domain.ScopeAdmin}}
+	secret, err := u.Issue(asAdmin(), &key)
+	require.NoError(t, err)
+
+	id, err := u.Authenticate(context.TODO(), secret)
+	require.NoError(t, err)
+	assert.True(t, id.IsAdmin())
+
+	keys, err := u.Fetch(domain.WithIdentity(context.TODO(), id))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestAPIKeyUseCase_RequiresAdmin(t *testing.T) {
+	u := NewAPIKeyUseCase(memory.NewAPIKeyRepository(), new(mocks.AuthorRepository), time.Second*2)
+	key := domain.APIKey{Name: "importer", Scopes: []string{domain.ScopeReadArticles}}
+
+	_, err := u.Issue(context.TODO(), &key)
+	assert.Equal(t, domain.ErrUnauthorized, err)
+
+	author := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "1", AuthorID: 1})
+	_, err = u.Issue(author, &key)
+	assert.True(t, errors.Is(err, domain.ErrForbidden))
+
+	scoped := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "key", Roles: []string{domain.RoleAdmin}, Scopes: []string{domain.ScopeWriteArticles}})
+	_, err = u.Fetch(scoped)
+	assert.True(t, errors.Is(err, domain.ErrForbidden))
+}
+
+func TestAPIKeyUseCase_IssueValidates(t *testing.T) {
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	mockAuthorRepo.On("GetByID", mock.Anything, int64(9)).Return(domain.Author{}, domain.ErrNotFound).Once()
+	u := NewAPIKeyUseCase(memory.NewAPIKeyRepository(), mockAuthorRepo, time.Second*2)
+
+	_, err := u.Issue(asAdmin(), &domain.APIKey{Name: "x", Scopes: []string{"delete:everything"}, AuthorID: 9})
+
+	var de *domain.Error
+	require.True(t, errors.As(err, &de))
+	assert.Equal(t, domain.CodeValidation, de.Code)
+	require.Len(t, de.Fields, 2)
+	assert.Equal(t, "scopes", de.Fields[0].Field)
+	assert.Equal(t, "author_id", de.Fields[1].Field)
+	mockAuthorRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyUseCase_AuthenticateRejectsMalformedKeys(t *testing.T) {
+	u := NewAPIKeyUseCase(memory.NewAPIKeyRepository(), new(mocks.AuthorRepository), time.Second*2)
+
+	for _, secret := range []string{"", "nope", "cak_", "cak_0123456789ab", "cak_0123456789abXsecret", "cak_0123456789ab_secret"} {
+		_, err := u.Authenticate(context.TODO(), secret)
+		assert.Equal(t, errInvalidKey, err, secret)
+	}
+}
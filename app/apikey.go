@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	keyRepo "github.com/angelRaynov/clean-architecture/apikey/repository/db"
+	keyUsecase "github.com/angelRaynov/clean-architecture/apikey/usecase"
+	authRepo "github.com/angelRaynov/clean-architecture/author/repository/db"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/server"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const apiKeyUsage = "usage: app apikey issue <name> <scope,...> [author id] | revoke <id> | list"
+
+// runAPIKey handles `app apikey issue|revoke|list`, which is how the first
+// admin key gets issued. The command acts as an admin. Like runMigrate it
+// returns errors instead of exiting so the connection is closed.
+func runAPIKey(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	if cfg.Database.InMemory() {
+		return errors.New("keys issued to the in-memory storage would be lost at once")
+	}
+
+	conn, dbDialect, err := server.OpenDatabase(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	keys := keyUsecase.NewAPIKeyUseCase(
+		keyRepo.NewAPIKeyRepository(conn, dbDialect),
+		authRepo.NewAuthorRepositoryWithDialect(conn, dbDialect),
+		cfg.ContextTimeout,
+	)
+	ctx := domain.WithIdentity(context.Background(), domain.Identity{Subject: "cli", Roles: []string{domain.RoleAdmin}})
+
+	switch {
+	case args[0] == "issue" && (len(args) == 3 || len(args) == 4):
+		key := domain.APIKey{Name: args[1], Scopes: strings.Split(args[2], ",")}
+		if len(args) == 4 {
+			key.AuthorID, err = strconv.ParseInt(args[3], 10, 64)
+			if err != nil {
+				return errors.New(apiKeyUsage)
+			}
+		}
+
+		secret, err := keys.Issue(ctx, &key)
+		if err != nil {
+			return err
+		}
+		log.Printf("issued key %d (%s); it is shown only once:", key.ID, key.Prefix)
+		fmt.Println(secret)
+	case args[0] == "revoke" && len(args) == 2:
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.New(apiKeyUsage)
+		}
+		if err = keys.Revoke(ctx, id); err != nil {
+			return err
+		}
+		log.Printf("revoked key %d", id)
+	case args[0] == "list" && len(args) == 1:
+		list, err := keys.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tAUTHOR\tLAST USED\tREVOKED")
+		for _, k := range list {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), k.AuthorID, formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+		}
+		return w.Flush()
+	default:
+		return errors.New(apiKeyUsage)
+	}
+
+	return nil
+}
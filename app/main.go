@@ -1,70 +1,51 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
-	artDelivery "github.com/angelRaynov/clean-architecture/article/delivery/http"
-	artMIddleware "github.com/angelRaynov/clean-architecture/article/delivery/http/middleware"
-	artRepo "github.com/angelRaynov/clean-architecture/article/repository/db"
-	artUsecase "github.com/angelRaynov/clean-architecture/article/usecase"
-	authRepo "github.com/angelRaynov/clean-architecture/author/repository/db"
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/joho/godotenv"
-	"github.com/labstack/echo"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/server"
+	"golang.org/x/exp/slog"
 	"log"
 	"os"
-	"strconv"
-	"time"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"))
-
-	fmt.Println(dsn)
-	conn, err := sql.Open("mysql", dsn)
-
+	cfg, args, err := config.Load(os.Args[1:])
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = conn.Ping()
-	if err != nil {
-		log.Fatal(err)
+	if len(args) > 0 && args[0] == "migrate" {
+		if err = runMigrate(cfg.Database, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	log.Println("Connected")
-
-	defer func() {
-		err = conn.Close()
-		if err != nil {
+	if len(args) > 0 && args[0] == "apikey" {
+		if err = runAPIKey(cfg, args[1:]); err != nil {
 			log.Fatal(err)
 		}
-	}()
-
-	e := echo.New()
-	middleware := artMIddleware.InitMiddleware()
-	e.Use(middleware.CORS)
-	authorRepo := authRepo.NewAuthorRepository(conn)
-	articleRepo := artRepo.NewArticleRepository(conn)
+		return
+	}
 
-	to, err := strconv.Atoi(os.Getenv("CTX_TIMEOUT"))
+	app, err := server.New(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
-	timoutContext := time.Duration(to) * time.Second
+	slog.SetDefault(app.Logger)
+	app.Logger.Info("starting", "config", fmt.Sprintf("%+v", cfg))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	articleUsecase := artUsecase.NewArticleUseCase(articleRepo, authorRepo, timoutContext)
-	artDelivery.NewArticleHandler(e, articleUsecase)
+	if err = app.Run(ctx); err != nil {
+		app.Logger.Error("stopped with an error", "err", err)
+		os.Exit(1)
+	}
 
-	log.Fatal(e.Start(os.Getenv("SERVER_ADDRESS")))
+	app.Logger.Info("stopped")
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/migration"
+	"github.com/angelRaynov/clean-architecture/server"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: app migrate up | down [steps] | status"
+
+// runMigrate handles `app migrate up|down|status` against the configured
+// database. It returns errors instead of exiting so the connection is closed.
+func runMigrate(cfg config.Database, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if cfg.InMemory() {
+		return errors.New("the in-memory storage has no schema to migrate")
+	}
+
+	conn, dbDialect, err := server.OpenDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m, err := migration.NewMigrator(conn, dbDialect)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			log.Printf("applied %d_%s", mig.Version, mig.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Println("schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New(migrateUsage)
+			}
+		}
+
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			log.Printf("reverted %d_%s", mig.Version, mig.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			log.Println("no migration to revert")
+		}
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = formatTime(&s.AppliedAt)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+// formatTime prints t in UTC, or "-" when it is nil.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
@@ -1,65 +1,249 @@
 package http
 
 import (
+	"github.com/angelRaynov/clean-architecture/cursor"
+	deliveryHttp "github.com/angelRaynov/clean-architecture/delivery/http"
 	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
 	"github.com/labstack/echo"
-	validator "gopkg.in/go-playground/validator.v9"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
-type ResponseError struct {
-	Message string `json:"message"`
-}
+// cursorList names the cursors of article lists.
+const cursorList = "articles"
+
+var errCursorSort = domain.NewError(domain.CodeBadInput, "the cursor belongs to a list in another order")
 
 type ArticleHandler struct {
 	ArticleUseCase domain.ArticleUseCase
+	PageSize       domain.PageSize
+	Cursors        *cursor.Codec
 }
 
 func NewArticleHandler(e *echo.Echo, useCase domain.ArticleUseCase) {
+	NewArticleHandlerWithPaging(e, useCase, domain.DefaultPageSize, cursor.NewCodec(nil))
+}
+
+// NewArticleHandlerWithPaging registers the article routes. pageSize must
+// match the one of the use case, and every replica must sign cursors with the
+// same key.
+func NewArticleHandlerWithPaging(e *echo.Echo, useCase domain.ArticleUseCase, pageSize domain.PageSize, cursors *cursor.Codec) {
 	handler := &ArticleHandler{
 		ArticleUseCase: useCase,
+		PageSize:       pageSize,
+		Cursors:        cursors.For(cursorList),
 	}
 
 	e.GET("/articles", handler.FetchArticle)
+	e.GET("/articles/search", handler.Search)
 	e.GET("/articles/:id", handler.GetByID)
 	e.POST("/articles", handler.Store)
+	e.PUT("/articles/:id", handler.Update)
+	e.PATCH("/articles/:id", handler.Patch)
 	e.DELETE("/articles/:id", handler.Delete)
+	e.GET("/authors/:id/articles", handler.FetchByAuthor)
 }
 
+// FetchArticle lists articles a page at a time, following cursors unless the
+// page or per_page parameter asks for numbered pages.
 func (ah *ArticleHandler) FetchArticle(ec echo.Context) error {
-	numString := ec.QueryParam("num")
-	num, _ := strconv.Atoi(numString)
+	filter, err := listFilter(ec)
+	if err != nil {
+		return err
+	}
+
+	if numbered(ec) {
+		return ah.fetchNumbered(ec, filter)
+	}
+
+	page, err := ah.pageRequest(ec)
+	if err != nil {
+		return err
+	}
 
-	cursor := ec.QueryParam("cursor")
 	ctx := ec.Request().Context()
 
-	listArticle, nextCursor, err := ah.ArticleUseCase.Fetch(ctx, cursor, int64(num))
+	listArticle, info, err := ah.ArticleUseCase.Fetch(ctx, filter, page)
 	if err != nil {
-		return ec.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return err
 	}
 
-	ec.Response().Header().Set(`X-Cursor`, nextCursor)
-	return ec.JSON(http.StatusOK, listArticle)
+	return ah.writeArticles(ec, listArticle, info, page)
 }
 
-func (ah *ArticleHandler) GetByID(ec echo.Context) error {
-	idString, err := strconv.Atoi(ec.Param("id"))
+func (ah *ArticleHandler) FetchByAuthor(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
+	}
+
+	page, err := ah.pageRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+
+	listArticle, info, err := ah.ArticleUseCase.FetchByAuthor(ctx, id, page)
+	if err != nil {
+		return err
+	}
+
+	return ah.writeArticles(ec, listArticle, info, page)
+}
 
+func (ah *ArticleHandler) Search(ec echo.Context) error {
+	query := ec.QueryParam("q")
+	if strings.TrimSpace(query) == "" {
+		return domain.NewError(domain.CodeBadInput, "the q parameter is required")
+	}
+
+	num, err := deliveryHttp.PageSize(ec, ah.PageSize)
 	if err != nil {
-		return ec.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return err
+	}
+
+	var after *domain.SearchPosition
+	if searchCursor := ec.QueryParam("cursor"); searchCursor != "" {
+		pos, err := ah.Cursors.DecodeSearch(searchCursor)
+		if err != nil {
+			return err
+		}
+		after = &pos
+	}
+
+	ctx := ec.Request().Context()
+	results, info, err := ah.ArticleUseCase.Search(ctx, query, after, num)
+	if err != nil {
+		return err
+	}
+
+	page := deliveryHttp.Page{Data: results, PageSize: num}
+	if info.HasNext && len(results) > 0 {
+		page.NextCursor = ah.Cursors.EncodeSearch(results[len(results)-1].Position())
+	}
+
+	return deliveryHttp.WritePage(ec, page)
+}
+
+// pageRequest reads the page parameters and the sort and order query
+// parameters. Without sort and order the page keeps the sort its cursor was
+// issued for.
+func (ah *ArticleHandler) pageRequest(ec echo.Context) (domain.PageRequest, error) {
+	page, err := deliveryHttp.PageRequest(ec, ah.PageSize, ah.Cursors)
+	if err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	if ec.QueryParam("sort") == "" && ec.QueryParam("order") == "" {
+		page.Sort = page.Sort.Normalize()
+		return page, nil
+	}
+
+	s, err := listSort(ec)
+	if err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	if (page.After != nil || page.Before != nil) && page.Sort != s {
+		return domain.PageRequest{}, errCursorSort
+	}
+
+	page.Sort = s
+	return page, nil
+}
+
+// listSort reads the sort and order query parameters.
+func listSort(ec echo.Context) (domain.Sort, error) {
+	s := domain.Sort{Field: domain.SortField(ec.QueryParam("sort"))}
+	switch ec.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		s.Desc = true
+	default:
+		return s, domain.NewError(domain.CodeBadInput, "order must be asc or desc")
+	}
+
+	s = s.Normalize()
+	if !s.Field.Valid() {
+		return s, domain.NewError(domain.CodeBadInput, "sort must be created_at, updated_at or title")
+	}
+
+	return s, nil
+}
+
+// listFilter reads the filters of the article list.
+func listFilter(ec echo.Context) (domain.ArticleFilter, error) {
+	filter := domain.ArticleFilter{TitlePrefix: ec.QueryParam("title_prefix")}
+
+	if authorID := ec.QueryParam("author_id"); authorID != "" {
+		id, err := strconv.ParseInt(authorID, 10, 64)
+		if err != nil {
+			return filter, domain.NewError(domain.CodeBadInput, "author_id must be an integer")
+		}
+		filter.AuthorID = id
+	}
+
+	times := []struct {
+		param string
+		field *time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"updated_from", &filter.UpdatedFrom},
+		{"updated_to", &filter.UpdatedTo},
+	}
+
+	for _, tp := range times {
+		value := ec.QueryParam(tp.param)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseTime(value)
+		if err != nil {
+			return filter, domain.NewError(domain.CodeBadInput, tp.param+" must be an RFC 3339 time or a date such as 2006-01-02")
+		}
+		*tp.field = t
+	}
+
+	return filter, nil
+}
+
+// parseTime accepts an RFC 3339 time or a date, which means its midnight in
+// UTC.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
+
+// writeArticles responds with a page of articles and the cursors of its
+// first and last articles.
+func (ah *ArticleHandler) writeArticles(ec echo.Context, list []domain.Article, info domain.PageInfo, page domain.PageRequest) error {
+	return deliveryHttp.WritePage(ec, deliveryHttp.NewPage(list, info, page, ah.Cursors, page.Sort.Position))
+}
+
+func (ah *ArticleHandler) GetByID(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
 	}
 
-	id := int64(idString)
 	ctx := ec.Request().Context()
 
 	article, err := ah.ArticleUseCase.GetByID(ctx, id)
 	if err != nil {
-		return ec.JSON(getStatusCode(err), ResponseError{
-			Message: err.Error(),
-		})
+		return err
 	}
 
+	setETag(ec, article.UpdatedAt)
 	return ec.JSON(http.StatusOK, article)
 }
 
@@ -67,66 +251,129 @@ func (ah *ArticleHandler) Store(ec echo.Context) error {
 	var article domain.Article
 	err := ec.Bind(&article)
 	if err != nil {
-		return ec.JSON(http.StatusUnprocessableEntity, err.Error())
+		return err
 	}
 
-	var ok bool
-	if ok, err = isValidRequest(&article); !ok {
-		return ec.JSON(http.StatusBadRequest, err.Error())
+	if err = isValidRequest(&article); err != nil {
+		return err
 	}
 
 	ctx := ec.Request().Context()
 	err = ah.ArticleUseCase.Store(ctx, &article)
 	if err != nil {
-		return ec.JSON(getStatusCode(err), ResponseError{
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return ec.JSON(http.StatusCreated, article)
 }
 
-func (ah *ArticleHandler) Delete(ec echo.Context) error {
-	idString, err := strconv.Atoi(ec.Param("id"))
+// Update replaces an article. An If-Match header carrying the article ETag makes
+// the update fail with 412 if someone else changed the article in the meantime.
+func (ah *ArticleHandler) Update(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
 	if err != nil {
-		return ec.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return err
 	}
 
-	id := int64(idString)
-	ctx := ec.Request().Context()
+	version, err := parseIfMatch(ec.Request().Header.Get("If-Match"))
+	if err != nil {
+		return err
+	}
 
-	err = ah.ArticleUseCase.Delete(ctx, id)
+	var article domain.Article
+	err = ec.Bind(&article)
 	if err != nil {
-		ec.JSON(getStatusCode(err), ResponseError{
-			Message: err.Error(),
-		})
+		return err
 	}
 
-	return ec.NoContent(http.StatusNoContent)
+	article.ID = id
+
+	if err = isValidRequest(&article); err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	err = ah.ArticleUseCase.Update(ctx, &article, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(ec, article.UpdatedAt)
+	return ec.JSON(http.StatusOK, article)
 }
 
-func getStatusCode(err error) int {
-	if err == nil {
-		return http.StatusOK
+// Patch applies a JSON merge patch (RFC 7386) to an article. The version that
+// was patched is used as the update precondition, so a concurrent write between
+// the read and the update results in 412 rather than a lost update.
+func (ah *ArticleHandler) Patch(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
 	}
 
-	switch err {
-	case domain.ErrNotFound:
-		return http.StatusNotFound
-	case domain.ErrConflict:
-		return http.StatusConflict
-	default:
-		return http.StatusInternalServerError
+	contentType := ec.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, mergePatchContentType) && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "expected "+mergePatchContentType)
+	}
+
+	version, err := parseIfMatch(ec.Request().Header.Get("If-Match"))
+	if err != nil {
+		return err
+	}
+
+	patch, err := io.ReadAll(ec.Request().Body)
+	if err != nil {
+		return domain.WrapError(domain.CodeBadInput, "the body could not be read", err)
+	}
+
+	ctx := ec.Request().Context()
+
+	existingArticle, err := ah.ArticleUseCase.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !version.IsZero() && !existingArticle.UpdatedAt.Equal(version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	article, err := applyMergePatch(existingArticle, patch)
+	if err != nil {
+		return domain.WrapError(domain.CodeBadInput, "the body is not a valid JSON merge patch", err)
+	}
+
+	article.ID = id
 
+	if err = isValidRequest(&article); err != nil {
+		return err
 	}
+
+	err = ah.ArticleUseCase.Update(ctx, &article, existingArticle.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	setETag(ec, article.UpdatedAt)
+	return ec.JSON(http.StatusOK, article)
 }
 
-func isValidRequest(a *domain.Article) (bool, error) {
-	validate := validator.New()
-	err := validate.Struct(a)
+func (ah *ArticleHandler) Delete(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	return true, nil
+	ctx := ec.Request().Context()
+
+	err = ah.ArticleUseCase.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ec.NoContent(http.StatusNoContent)
+}
+
+func isValidRequest(a *domain.Article) error {
+	validate := problem.NewValidator()
+	return validate.Struct(a)
 }
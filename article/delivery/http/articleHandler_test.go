@@ -0,0 +1,499 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	articleHttp "github.com/angelRaynov/clean-architecture/article/delivery/http"
+	artMemory "github.com/angelRaynov/clean-architecture/article/repository/memory"
+	"github.com/angelRaynov/clean-architecture/article/usecase"
+	"github.com/angelRaynov/clean-architecture/auth"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/cursor"
+	deliveryHttp "github.com/angelRaynov/clean-architecture/delivery/http"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const hmacSecret = "0123456789abcdef0123456789abcdef"
+
+type server struct {
+	t    *testing.T
+	echo *echo.Echo
+	// token is sent as the bearer token of every request unless empty.
+	token string
+}
+
+func newServer(t *testing.T) (*server, domain.AuthorRepository) {
+	authorRepo := authMemory.NewAuthorRepository()
+	articleRepo := artMemory.NewArticleRepository()
+
+	authenticator, err := auth.New(config.Auth{HMACSecret: hmacSecret})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(authenticator.Middleware)
+	articleHttp.NewArticleHandler(e, usecase.NewArticleUseCase(articleRepo, authorRepo, time.Second*2))
+
+	return &server{t: t, echo: e, token: signToken(t, auth.Claims{AuthorID: 1})}, authorRepo
+}
+
+func signToken(t *testing.T, claims auth.Claims) string {
+	claims.Subject = "test"
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(hmacSecret))
+	require.NoError(t, err)
+
+	return token
+}
+
+func (s *server) do(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if s.token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+s.token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestArticleHandler_EndToEnd(t *testing.T) {
+	s, authorRepo := newServer(t)
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+
+	rec := s.do(http.MethodPost, "/articles", `{"title":"It","content":"A clown","Author":{"id":1}}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created domain.Article
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, int64(1), created.ID)
+
+	rec = s.do(http.MethodPost, "/articles", `{"title":"It","content":"Again"}`, nil)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = s.do(http.MethodGet, "/articles/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	var fetched domain.Article
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fetched))
+	assert.Equal(t, "King", fetched.Author.Name)
+
+	rec = s.do(http.MethodPut, "/articles/1", `{"title":"It","content":"A scary clown","Author":{"id":1}}`,
+		http.Header{"If-Match": {etag}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	rec = s.do(http.MethodPut, "/articles/1", `{"title":"It","content":"Lost update"}`,
+		http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		rec = s.do(method, "/articles/1", `{"title":"It","content":"Blind write"}`, nil)
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code, method)
+		assert.Equal(t, domain.CodePreconditionRequired, decodeProblem(t, rec).Code, method)
+	}
+
+	rec = s.do(http.MethodPatch, "/articles/1", `{"content":null,"title":"It (1986)"}`,
+		http.Header{echo.HeaderContentType: {"application/merge-patch+json"}, "If-Match": {"*"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var patched domain.Article
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patched))
+	assert.Equal(t, "It (1986)", patched.Title)
+	assert.Empty(t, patched.Content)
+	assert.Equal(t, int64(1), patched.Author.ID)
+
+	rec = s.do(http.MethodGet, "/articles", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var list []domain.Article
+	page := deliveryHttp.Page{Data: &list}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(10), page.PageSize)
+	assert.False(t, page.HasMore)
+
+	rec = s.do(http.MethodGet, "/authors/1/articles", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = s.do(http.MethodGet, "/articles/search?q=1986", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var results []domain.ArticleSearchResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deliveryHttp.Page{Data: &results}))
+	assert.Len(t, results, 1)
+
+	s.token = signToken(t, auth.Claims{AuthorID: 2})
+	rec = s.do(http.MethodDelete, "/articles/1", "", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	s.token = ""
+	rec = s.do(http.MethodDelete, "/articles/1", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+
+	rec = s.do(http.MethodDelete, "/articles/1", "", http.Header{echo.HeaderAuthorization: {"Bearer not-a-token"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, domain.CodeUnauthorized, decodeProblem(t, rec).Code)
+
+	s.token = signToken(t, auth.Claims{Roles: []string{domain.RoleAdmin}})
+	rec = s.do(http.MethodDelete, "/articles/1", "", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = s.do(http.MethodDelete, "/articles/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+
+	assert.Equal(t, problem.ContentType, rec.Header().Get(echo.HeaderContentType))
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, rec.Code, p.Status)
+
+	return p
+}
+
+func TestArticleHandler_Problems(t *testing.T) {
+	s, authorRepo := newServer(t)
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+
+	rec := s.do(http.MethodGet, "/articles/abc", "", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, domain.CodeBadInput, p.Code)
+	assert.Equal(t, "/articles/abc", p.Instance)
+
+	rec = s.do(http.MethodGet, "/articles/7", "", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, domain.CodeNotFound, decodeProblem(t, rec).Code)
+
+	rec = s.do(http.MethodPost, "/articles", `{"title":`, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code)
+
+	rec = s.do(http.MethodGet, "/articles?cursor=%25%25%25", "", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code)
+
+	rec = s.do(http.MethodPatch, "/articles/1", `{}`, http.Header{echo.HeaderContentType: {"text/plain"}})
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	decodeProblem(t, rec)
+}
+
+func TestArticleHandler_MissingAuthor(t *testing.T) {
+	s, authorRepo := newServer(t)
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+
+	rec := s.do(http.MethodPost, "/articles", `{"title":"It","content":"A clown","Author":{"id":1}}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, authorRepo.Delete(context.TODO(), 1))
+
+	rec = s.do(http.MethodGet, "/articles/1", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, "the article outlives its author")
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, true, body["author_missing"])
+}
+
+func TestArticleHandler_Paging(t *testing.T) {
+	articleRepo := artMemory.NewArticleRepository()
+	authorRepo := authMemory.NewAuthorRepository()
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+
+	// It and Carrie share a timestamp; paging must not skip either.
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, title := range []string{"It", "Carrie", "Misery"} {
+		article := domain.Article{Title: title, Author: domain.Author{ID: 1}, CreatedAt: created.Add(time.Duration(i/2) * time.Hour)}
+		require.NoError(t, articleRepo.Store(context.TODO(), &article))
+	}
+
+	pageSize := domain.PageSize{Default: 1, Max: 3}
+	cursors := cursor.NewCodec([]byte(hmacSecret))
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	articleHttp.NewArticleHandlerWithPaging(e, usecase.NewArticleUseCaseWithPageSize(articleRepo, authorRepo, time.Second*2, pageSize), pageSize, cursors)
+	s := &server{t: t, echo: e}
+
+	get := func(target string) ([]string, deliveryHttp.Page) {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var list []domain.Article
+		page := deliveryHttp.Page{Data: &list}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, rec.Header().Get("X-Cursor"), page.NextCursor)
+
+		titles := make([]string, 0, len(list))
+		for _, a := range list {
+			titles = append(titles, a.Title)
+		}
+		return titles, page
+	}
+
+	titles, first := get("/articles")
+	assert.Equal(t, []string{"It"}, titles)
+	assert.True(t, first.HasMore)
+	assert.Empty(t, first.PrevCursor)
+	assert.Equal(t, int64(1), first.PageSize)
+
+	titles, second := get("/articles?num=2&after=" + first.NextCursor)
+	assert.Equal(t, []string{"Carrie", "Misery"}, titles)
+	assert.False(t, second.HasMore)
+	assert.Empty(t, second.NextCursor)
+	assert.NotEmpty(t, second.PrevCursor)
+
+	titles, back := get("/articles?before=" + second.PrevCursor)
+	assert.Equal(t, []string{"It"}, titles)
+	assert.Empty(t, back.PrevCursor, "the first page has nothing before it")
+	assert.True(t, back.HasMore)
+
+	titles, _ = get("/articles?cursor=" + first.NextCursor)
+	assert.Equal(t, []string{"Carrie"}, titles, "cursor is an alias of after")
+
+	tampered := []byte(first.NextCursor)
+	tampered[3] ^= 1
+
+	for _, target := range []string{
+		"/articles?num=ten",
+		"/articles?num=-1",
+		"/articles?num=4",
+		"/authors/1/articles?num=4",
+		"/articles/search?q=it&num=x",
+		"/articles?after=" + string(tampered),
+		"/articles?after=" + cursor.NewCodec(nil).Encode(domain.Sort{}, domain.Position{ID: 1}),
+		"/articles?after=" + cursor.NewCodec([]byte(hmacSecret)).For("authors").Encode(domain.Sort{}, domain.Position{ID: 1}),
+		"/articles?after=" + first.NextCursor + "&before=" + second.PrevCursor,
+	} {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, target)
+	}
+}
+
+func TestArticleHandler_SearchPages(t *testing.T) {
+	s, authorRepo := newServer(t)
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+	for _, title := range []string{"Roses", "More roses", "Roses again"} {
+		rec := s.do(http.MethodPost, "/articles", `{"title":"`+title+`","content":"roses"}`, nil)
+		require.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	search := func(target string) ([]domain.ArticleSearchResult, deliveryHttp.Page) {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var results []domain.ArticleSearchResult
+		page := deliveryHttp.Page{Data: &results}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return results, page
+	}
+
+	results, first := search("/articles/search?q=roses&num=2")
+	assert.Len(t, results, 2)
+	require.True(t, first.HasMore)
+
+	results, second := search("/articles/search?q=roses&num=2&cursor=" + first.NextCursor)
+	assert.Len(t, results, 1)
+	assert.False(t, second.HasMore)
+
+	_, all := search("/articles/search?q=roses&num=3")
+	assert.False(t, all.HasMore, "a full last page has nothing after it")
+
+	tampered := []byte(first.NextCursor)
+	tampered[3] ^= 1
+	listCursor := cursor.NewCodec(nil).Encode(domain.Sort{}, domain.Position{ID: 1})
+	for _, c := range []string{string(tampered), listCursor, "MC41LDE"} {
+		rec := s.do(http.MethodGet, "/articles/search?q=roses&cursor="+c, "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, c)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, c)
+	}
+}
+
+func TestArticleHandler_SortAndFilter(t *testing.T) {
+	articleRepo := artMemory.NewArticleRepository()
+	authorRepo := authMemory.NewAuthorRepository()
+	for _, name := range []string{"King", "Austen"} {
+		require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: name}))
+	}
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, title := range []string{"Misery", "Emma", "Carrie", "Persuasion"} {
+		article := domain.Article{
+			Title:     title,
+			Author:    domain.Author{ID: int64(i%2 + 1)},
+			CreatedAt: created.AddDate(0, 0, i),
+			UpdatedAt: created.AddDate(0, 0, 10-i),
+		}
+		require.NoError(t, articleRepo.Store(context.TODO(), &article))
+	}
+
+	pageSize := domain.PageSize{Default: 2, Max: 10}
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	articleHttp.NewArticleHandlerWithPaging(e, usecase.NewArticleUseCaseWithPageSize(articleRepo, authorRepo, time.Second*2, pageSize), pageSize, cursor.NewCodec([]byte(hmacSecret)))
+	s := &server{t: t, echo: e}
+
+	get := func(target string) ([]string, deliveryHttp.Page) {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var list []domain.Article
+		page := deliveryHttp.Page{Data: &list}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+
+		titles := make([]string, 0, len(list))
+		for _, a := range list {
+			titles = append(titles, a.Title)
+		}
+		return titles, page
+	}
+
+	titles, first := get("/articles?sort=title")
+	assert.Equal(t, []string{"Carrie", "Emma"}, titles)
+
+	titles, _ = get("/articles?after=" + first.NextCursor)
+	assert.Equal(t, []string{"Misery", "Persuasion"}, titles, "the cursor keeps its sort")
+
+	titles, desc := get("/articles?sort=updated_at&order=desc&num=3")
+	assert.Equal(t, []string{"Misery", "Emma", "Carrie"}, titles)
+
+	titles, _ = get("/articles?sort=updated_at&order=desc&after=" + desc.NextCursor)
+	assert.Equal(t, []string{"Persuasion"}, titles)
+
+	titles, _ = get("/articles?sort=title&order=desc&author_id=2")
+	assert.Equal(t, []string{"Persuasion", "Emma"}, titles)
+
+	titles, _ = get("/articles?created_from=2020-01-02&created_to=2020-01-04T00:00:00Z")
+	assert.Equal(t, []string{"Emma", "Carrie"}, titles)
+
+	titles, _ = get("/articles?title_prefix=per&updated_from=2020-01-01")
+	assert.Equal(t, []string{"Persuasion"}, titles)
+
+	for _, target := range []string{
+		"/articles?sort=content",
+		"/articles?order=up",
+		"/articles?author_id=king",
+		"/articles?created_from=yesterday",
+		"/articles?sort=created_at&after=" + first.NextCursor,
+		"/articles?order=desc&after=" + first.NextCursor,
+	} {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, target)
+	}
+
+	rec := s.do(http.MethodGet, "/articles?created_from=2020-01-03&created_to=2020-01-02", "", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, domain.CodeValidation, decodeProblem(t, rec).Code)
+}
+
+func TestArticleHandler_NumberedPages(t *testing.T) {
+	articleRepo := artMemory.NewArticleRepository()
+	authorRepo := authMemory.NewAuthorRepository()
+	require.NoError(t, authorRepo.Store(context.TODO(), &domain.Author{Name: "King"}))
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, title := range []string{"Carrie", "It", "Misery", "Cujo", "Christine"} {
+		article := domain.Article{Title: title, Author: domain.Author{ID: 1}, CreatedAt: created.AddDate(0, 0, i)}
+		require.NoError(t, articleRepo.Store(context.TODO(), &article))
+	}
+
+	pageSize := domain.PageSize{Default: 2, Max: 10}
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	articleHttp.NewArticleHandlerWithPaging(e, usecase.NewArticleUseCaseWithPageSize(articleRepo, authorRepo, time.Second*2, pageSize), pageSize, cursor.NewCodec([]byte(hmacSecret)))
+	s := &server{t: t, echo: e}
+
+	get := func(target string) ([]string, deliveryHttp.Page, http.Header) {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var list []domain.Article
+		page := deliveryHttp.Page{Data: &list}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+
+		titles := make([]string, 0, len(list))
+		for _, a := range list {
+			titles = append(titles, a.Title)
+		}
+		return titles, page, rec.Header()
+	}
+
+	titles, page, header := get("/articles?page=2&sort=title")
+	assert.Equal(t, []string{"Cujo", "It"}, titles)
+	assert.True(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, int64(2), page.PageSize)
+	assert.Equal(t, "5", header.Get(articleHttp.HeaderTotalCount))
+	assert.Equal(t, `<http://example.com/articles?page=1&per_page=2&sort=title>; rel="first", `+
+		`<http://example.com/articles?page=1&per_page=2&sort=title>; rel="prev", `+
+		`<http://example.com/articles?page=3&per_page=2&sort=title>; rel="next", `+
+		`<http://example.com/articles?page=3&per_page=2&sort=title>; rel="last"`, header.Get("Link"))
+
+	titles, page, header = get("/articles?page=1&per_page=3&title_prefix=c")
+	assert.Equal(t, []string{"Carrie", "Cujo", "Christine"}, titles)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, "3", header.Get(articleHttp.HeaderTotalCount))
+	assert.Equal(t, `<http://example.com/articles?page=1&per_page=3&title_prefix=c>; rel="first", `+
+		`<http://example.com/articles?page=1&per_page=3&title_prefix=c>; rel="last"`, header.Get("Link"))
+
+	titles, _, header = get("/articles?page=9")
+	assert.Empty(t, titles)
+	assert.Contains(t, header.Get("Link"), `page=3&per_page=2>; rel="prev"`)
+
+	for _, target := range []string{
+		"/articles?page=0",
+		"/articles?page=two",
+		"/articles?per_page=11",
+		"/articles?page=1&num=2",
+		"/articles?page=1&cursor=abc",
+		"/articles?page=1&sort=content",
+	} {
+		rec := s.do(http.MethodGet, target, "", nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, target)
+	}
+}
+
+func TestArticleHandler_InternalErrorsDoNotLeak(t *testing.T) {
+	mockUCase := new(mocks.ArticleUseCase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).
+		Return(domain.Article{}, errors.New("dial tcp 10.0.0.5:3306: connect: connection refused"))
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	articleHttp.NewArticleHandler(e, mockUCase)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/articles/1", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, domain.CodeInternal, p.Code)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/labstack/echo"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errETagMismatch = domain.NewError(domain.CodePreconditionFailed, "If-Match does not name a current version of the article")
+
+var errIfMatchRequired = domain.NewError(domain.CodePreconditionRequired, `If-Match is required: send the ETag of the version being changed, or "*" to replace any version`)
+
+// versionETag renders the article version (its updated_at) as a strong ETag.
+func versionETag(t time.Time) string {
+	return strconv.Quote(strconv.FormatInt(t.UnixNano(), 10))
+}
+
+func setETag(ec echo.Context, t time.Time) {
+	ec.Response().Header().Set("ETag", versionETag(t))
+}
+
+// parseIfMatch turns an If-Match header into the version it requires. "*"
+// yields the zero time, meaning any current version is accepted. Writes
+// without the header are refused, so a client cannot overwrite a change it
+// has not seen by leaving it out.
+func parseIfMatch(header string) (time.Time, error) {
+	header = strings.TrimSpace(header)
+	switch header {
+	case "":
+		return time.Time{}, errIfMatchRequired
+	case "*":
+		return time.Time{}, nil
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return time.Time{}, errETagMismatch
+	}
+
+	nanos, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return time.Time{}, errETagMismatch
+	}
+
+	return time.Unix(0, nanos), nil
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/domain"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// applyMergePatch applies an RFC 7386 JSON merge patch to the article.
+func applyMergePatch(a domain.Article, patch []byte) (domain.Article, error) {
+	var patchDoc interface{}
+	err := json.Unmarshal(patch, &patchDoc)
+	if err != nil {
+		return domain.Article{}, err
+	}
+
+	original, err := json.Marshal(a)
+	if err != nil {
+		return domain.Article{}, err
+	}
+
+	var targetDoc interface{}
+	err = json.Unmarshal(original, &targetDoc)
+	if err != nil {
+		return domain.Article{}, err
+	}
+
+	merged, err := json.Marshal(mergePatch(targetDoc, patchDoc))
+	if err != nil {
+		return domain.Article{}, err
+	}
+
+	var res domain.Article
+	err = json.Unmarshal(merged, &res)
+
+	return res, err
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+
+	return targetObj
+}
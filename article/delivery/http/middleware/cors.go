@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/labstack/echo"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// corsPolicy is config.CORS prepared for matching requests against it.
+type corsPolicy struct {
+	anyOrigin     bool
+	origins       map[string]bool
+	patterns      []originPattern
+	methods       map[string]bool
+	allowMethods  string
+	anyHeader     bool
+	headers       map[string]bool
+	exposeHeaders string
+	credentials   bool
+	maxAge        string
+}
+
+// originPattern matches origins around a single '*', such as
+// https://*.example.com.
+type originPattern struct {
+	prefix, suffix string
+}
+
+func (p originPattern) match(origin string) bool {
+	return len(origin) > len(p.prefix)+len(p.suffix) &&
+		strings.HasPrefix(origin, p.prefix) &&
+		strings.HasSuffix(origin, p.suffix)
+}
+
+func newCORSPolicy(cfg config.CORS) corsPolicy {
+	p := corsPolicy{
+		origins:       map[string]bool{},
+		methods:       map[string]bool{},
+		headers:       map[string]bool{},
+		credentials:   cfg.AllowCredentials,
+		exposeHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			i := strings.Index(origin, "*")
+			p.patterns = append(p.patterns, originPattern{prefix: origin[:i], suffix: origin[i+1:]})
+		default:
+			p.origins[origin] = true
+		}
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		method = strings.ToUpper(method)
+		p.methods[method] = true
+		methods = append(methods, method)
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return p
+}
+
+func (p corsPolicy) allowsOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.match(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsHeaders reports whether every header listed in an
+// Access-Control-Request-Headers value is allowed.
+func (p corsPolicy) allowsHeaders(requested string) bool {
+	if p.anyHeader {
+		return true
+	}
+
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CORS applies the configured cross-origin policy. Preflight requests are
+// answered here with 204 and never reach the router; a preflight asking for
+// an origin, method or header outside the policy gets no CORS headers, so the
+// browser refuses the actual request.
+func (m *Middleware) CORS(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		header := c.Response().Header()
+		origin := req.Header.Get(echo.HeaderOrigin)
+
+		preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+		if preflight {
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+
+			if origin == "" || !m.cors.allowsOrigin(origin) {
+				return c.NoContent(http.StatusNoContent)
+			}
+
+			requestedHeaders := req.Header.Get(echo.HeaderAccessControlRequestHeaders)
+			if !m.cors.methods[strings.ToUpper(req.Header.Get(echo.HeaderAccessControlRequestMethod))] ||
+				!m.cors.allowsHeaders(requestedHeaders) {
+				return c.NoContent(http.StatusNoContent)
+			}
+
+			m.setAllowOrigin(header, origin)
+			header.Set(echo.HeaderAccessControlAllowMethods, m.cors.allowMethods)
+			if requestedHeaders != "" {
+				header.Set(echo.HeaderAccessControlAllowHeaders, requestedHeaders)
+			}
+			if m.cors.maxAge != "" {
+				header.Set(echo.HeaderAccessControlMaxAge, m.cors.maxAge)
+			}
+
+			return c.NoContent(http.StatusNoContent)
+		}
+
+		if !m.cors.anyOrigin || m.cors.credentials {
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+		}
+
+		if origin != "" && m.cors.allowsOrigin(origin) {
+			m.setAllowOrigin(header, origin)
+			if m.cors.exposeHeaders != "" {
+				header.Set(echo.HeaderAccessControlExposeHeaders, m.cors.exposeHeaders)
+			}
+		}
+
+		return next(c)
+	}
+}
+
+// setAllowOrigin answers "*" when any origin is allowed without credentials,
+// and echoes the origin otherwise, as browsers require for credentials.
+func (m *Middleware) setAllowOrigin(header http.Header, origin string) {
+	if m.cors.anyOrigin && !m.cors.credentials {
+		header.Set(echo.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+	}
+
+	if m.cors.credentials {
+		header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+	}
+}
@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"github.com/angelRaynov/clean-architecture/article/delivery/http/middleware"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSServer(cors config.CORS) *echo.Echo {
+	m := middleware.InitMiddleware(slog.Default(), cors)
+
+	e := echo.New()
+	e.Use(m.CORS)
+	e.GET("/articles", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.DELETE("/articles/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	return e
+}
+
+func TestCORSPreflight(t *testing.T) {
+	e := newCORSServer(config.CORS{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedMethods:   []string{"GET", "DELETE"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	tests := map[string]struct {
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		"exact origin":         {"https://app.example.com", "DELETE", "authorization", true},
+		"wildcard origin":      {"https://pr-42.preview.example.com", "DELETE", "", true},
+		"bare wildcard suffix": {"https://.preview.example.com", "DELETE", "", false},
+		"unknown origin":       {"https://evil.example.org", "DELETE", "", false},
+		"method not allowed":   {"https://app.example.com", "PUT", "", false},
+		"header not allowed":   {"https://app.example.com", "DELETE", "Authorization, X-Secret", false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/articles/1", nil)
+			req.Header.Set(echo.HeaderOrigin, tc.origin)
+			req.Header.Set(echo.HeaderAccessControlRequestMethod, tc.method)
+			if tc.headers != "" {
+				req.Header.Set(echo.HeaderAccessControlRequestHeaders, tc.headers)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderOrigin)
+			if !tc.allowed {
+				assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+				return
+			}
+
+			assert.Equal(t, tc.origin, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+			assert.Equal(t, "GET, DELETE", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+			assert.Equal(t, tc.headers, rec.Header().Get(echo.HeaderAccessControlAllowHeaders))
+			assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+			assert.Equal(t, "3600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+		})
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	e := newCORSServer(config.Default().CORS)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://anywhere.example")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlExposeHeaders), "ETag, X-Cursor, X-Request-ID")
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+
+	e = newCORSServer(config.CORS{AllowedOrigins: []string{"https://app.example.com"}})
+
+	req = httptest.NewRequest(http.MethodGet, "/articles", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://other.example.com")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "the server answers, the browser enforces the policy")
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, echo.HeaderOrigin, rec.Header().Get(echo.HeaderVary))
+}
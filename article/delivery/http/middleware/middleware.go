@@ -1,17 +1,99 @@
 package middleware
 
-import "github.com/labstack/echo"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"time"
+)
 
 type Middleware struct {
+	Logger *slog.Logger
+
+	cors corsPolicy
+}
+
+// RequestID tags every request with an ID, reusing a well-formed X-Request-ID
+// sent by the client, and echoes it back. The request context carries the ID
+// and a logger already tagged with it and the route, which writes one access
+// line when the request is done.
+func (m *Middleware) RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		req := c.Request()
+
+		id := req.Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		logger := m.Logger.With("request_id", id, "method", req.Method, "route", route)
+		if sc := trace.SpanContextFromContext(req.Context()); sc.IsValid() {
+			logger = logger.With("trace_id", sc.TraceID().String())
+		}
+
+		ctx := logging.WithRequestID(logging.WithContext(req.Context(), logger), id)
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil {
+			status = problem.StatusCode(err)
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(ctx, level, "request", "path", req.URL.Path, "status", status, "duration", time.Since(start))
+
+		return err
+	}
+}
+
+// validRequestID accepts short IDs made of letters, digits, '-', '_' and
+// '.', so a client cannot inject anything odd into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
 }
 
-func (m *Middleware) CORS(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(context echo.Context) error {
-		context.Response().Header().Set("Access-Control-Allow-Origin", "*")
-		return next(context)
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
 	}
+
+	return hex.EncodeToString(b)
 }
 
-func InitMiddleware() *Middleware {
-	return &Middleware{}
+func InitMiddleware(logger *slog.Logger, cors config.CORS) *Middleware {
+	return &Middleware{
+		Logger: logger,
+		cors:   newCORSPolicy(cors),
+	}
 }
@@ -0,0 +1,70 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/article/delivery/http/middleware"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	m := middleware.InitMiddleware(logging.New(&buf, slog.LevelInfo), config.Default().CORS)
+
+	e := echo.New()
+	e.Use(m.RequestID)
+	e.GET("/articles/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		logging.FromContext(ctx).Info("handled")
+		return c.String(http.StatusOK, logging.RequestID(ctx))
+	})
+
+	tests := map[string]struct {
+		header string
+		reused bool
+	}{
+		"generated": {header: "", reused: false},
+		"reused":    {header: "client-id_1.2", reused: true},
+		"rejected":  {header: "bad id\n{}", reused: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(echo.GET, "/articles/1", nil)
+			if tc.header != "" {
+				req.Header.Set(echo.HeaderXRequestID, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(echo.HeaderXRequestID)
+			require.NotEmpty(t, id)
+			assert.Equal(t, id, rec.Body.String(), "the handler sees the same id")
+			assert.Equal(t, tc.reused, id == tc.header)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+			for _, l := range lines {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(l), &line))
+				assert.Equal(t, id, line["request_id"])
+				assert.Equal(t, "/articles/:id", line["route"])
+			}
+
+			var access map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+			assert.Equal(t, "request", access["msg"])
+			assert.Equal(t, float64(http.StatusOK), access["status"])
+		})
+	}
+}
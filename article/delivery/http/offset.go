@@ -0,0 +1,121 @@
+package http
+
+import (
+	"fmt"
+	deliveryHttp "github.com/angelRaynov/clean-architecture/delivery/http"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/labstack/echo"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HeaderTotalCount carries the number of items across every numbered page.
+const HeaderTotalCount = "X-Total-Count"
+
+var errNumberedAndCursor = domain.NewError(domain.CodeBadInput, "page and per_page cannot be combined with num, cursor, after or before")
+
+var errInvalidPage = domain.NewError(domain.CodeBadInput, "page must be a positive integer")
+
+// numbered reports whether the request asks for a numbered page.
+func numbered(ec echo.Context) bool {
+	return ec.QueryParam("page") != "" || ec.QueryParam("per_page") != ""
+}
+
+// offsetRequest reads the page, per_page, sort and order query parameters.
+// Pages count from 1.
+func (ah *ArticleHandler) offsetRequest(ec echo.Context) (domain.OffsetRequest, error) {
+	for _, param := range []string{"num", "cursor", "after", "before"} {
+		if ec.QueryParam(param) != "" {
+			return domain.OffsetRequest{}, errNumberedAndCursor
+		}
+	}
+
+	page := domain.OffsetRequest{Page: 1}
+	if pageString := ec.QueryParam("page"); pageString != "" {
+		n, err := strconv.ParseInt(pageString, 10, 64)
+		if err != nil || n < 1 {
+			return domain.OffsetRequest{}, errInvalidPage
+		}
+		page.Page = n
+	}
+
+	if perPage := ec.QueryParam("per_page"); perPage != "" {
+		n, err := strconv.ParseInt(perPage, 10, 64)
+		if err != nil || n < 1 || n > ah.PageSize.Max {
+			return domain.OffsetRequest{}, domain.NewError(domain.CodeBadInput, fmt.Sprintf("per_page must be between 1 and %d", ah.PageSize.Max))
+		}
+		page.PerPage = n
+	} else {
+		page.PerPage = ah.PageSize.Default
+	}
+
+	var err error
+	page.Sort, err = listSort(ec)
+	if err != nil {
+		return domain.OffsetRequest{}, err
+	}
+
+	return page, nil
+}
+
+// fetchNumbered responds with a numbered page of articles. The total goes in
+// the X-Total-Count header and the first, prev, next and last pages in the
+// Link header.
+func (ah *ArticleHandler) fetchNumbered(ec echo.Context, filter domain.ArticleFilter) error {
+	page, err := ah.offsetRequest(ec)
+	if err != nil {
+		return err
+	}
+
+	list, total, err := ah.ArticleUseCase.FetchPage(ec.Request().Context(), filter, page)
+	if err != nil {
+		return err
+	}
+
+	last := (total + page.PerPage - 1) / page.PerPage
+	if last < 1 {
+		last = 1
+	}
+
+	header := ec.Response().Header()
+	header.Set(HeaderTotalCount, strconv.FormatInt(total, 10))
+	header.Set("Link", pageLinks(ec, page, last))
+
+	return ec.JSON(http.StatusOK, deliveryHttp.Page{Data: list, HasMore: page.Page < last, PageSize: page.PerPage})
+}
+
+// pageLinks returns the RFC 5988 Link header value pointing at the first,
+// previous, next and last pages. The links keep every other query parameter
+// of the request.
+func pageLinks(ec echo.Context, page domain.OffsetRequest, last int64) string {
+	u := *ec.Request().URL
+	u.Scheme = ec.Scheme()
+	u.Host = ec.Request().Host
+
+	link := func(n int64, rel string) string {
+		query := u.Query()
+		query.Set("page", strconv.FormatInt(n, 10))
+		query.Set("per_page", strconv.FormatInt(page.PerPage, 10))
+		u.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if page.Page > 1 {
+		links = append(links, link(minInt64(page.Page-1, last), "prev"))
+	}
+	if page.Page < last {
+		links = append(links, link(page.Page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+
+	return strings.Join(links, ", ")
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"strings"
+)
+
+// sortColumns lists the columns lists may be ordered by. Sort fields are
+// looked up here and never written into a query as they come.
+var sortColumns = map[domain.SortField]string{
+	domain.SortCreatedAt: "created_at",
+	domain.SortUpdatedAt: "updated_at",
+	domain.SortTitle:     "title",
+}
+
+// listQuery builds the SELECT of an article list. Its SQL only ever holds
+// fixed fragments and column names from sortColumns; every value the client
+// chose is a placeholder argument.
+type listQuery struct {
+	dialect    dialect.Dialect
+	conditions []string
+	args       []interface{}
+	order      string
+	limit      int64
+	offset     int64
+}
+
+func (q *listQuery) where(condition string, args ...interface{}) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+}
+
+// filter adds the conditions of f.
+func (q *listQuery) filter(f domain.ArticleFilter) {
+	if f.AuthorID != 0 {
+		q.where("author_id = ?", f.AuthorID)
+	}
+	if !f.CreatedFrom.IsZero() {
+		q.where("created_at >= ?", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		q.where("created_at < ?", f.CreatedTo)
+	}
+	if !f.UpdatedFrom.IsZero() {
+		q.where("updated_at >= ?", f.UpdatedFrom)
+	}
+	if !f.UpdatedTo.IsZero() {
+		q.where("updated_at < ?", f.UpdatedTo)
+	}
+	if f.TitlePrefix != "" {
+		q.where(q.dialect.Like("title"), escapeLike(f.TitlePrefix)+"%")
+	}
+}
+
+// page orders the query by page.Sort and keeps the rows past its cursor,
+// plus one to tell whether there are more. Pages before a cursor are read
+// backwards.
+func (q *listQuery) page(page domain.PageRequest) {
+	by := page.Sort.Normalize()
+	column := sortColumns[by.Field]
+
+	// Rows after a cursor in ascending order, or before it in descending
+	// order, have greater keys.
+	backwards := page.Before != nil
+	op, dir := ">", "ASC"
+	if by.Desc != backwards {
+		op, dir = "<", "DESC"
+	}
+
+	cursor := page.After
+	if backwards {
+		cursor = page.Before
+	}
+
+	if cursor != nil {
+		value := interface{}(cursor.Time)
+		if by.Field == domain.SortTitle {
+			value = cursor.Title
+		}
+		q.where("("+column+" "+op+" ? OR ("+column+" = ? AND id "+op+" ?))", value, value, cursor.ID)
+	}
+
+	q.order = column + " " + dir + ", id " + dir
+	q.limit = page.Num + 1
+}
+
+// offsetPage orders the query by page.Sort and keeps the rows of the
+// numbered page.
+func (q *listQuery) offsetPage(page domain.OffsetRequest) {
+	by := page.Sort.Normalize()
+	column := sortColumns[by.Field]
+
+	dir := "ASC"
+	if by.Desc {
+		dir = "DESC"
+	}
+
+	q.order = column + " " + dir + ", id " + dir
+	q.limit = page.PerPage
+	q.offset = page.Offset()
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+
+	return ` WHERE ` + strings.Join(q.conditions, " AND ")
+}
+
+// build returns the SQL and its arguments.
+func (q *listQuery) build() (string, []interface{}) {
+	query := `SELECT id, title, content, author_id, updated_at, created_at FROM article` +
+		q.whereClause() + ` ORDER BY ` + q.order + ` LIMIT ?`
+	args := append(q.args, q.limit)
+
+	if q.offset > 0 {
+		query += ` OFFSET ?`
+		args = append(args, q.offset)
+	}
+
+	return query, args
+}
+
+// count returns the SQL counting the rows the conditions keep, and its
+// arguments.
+func (q *listQuery) count() (string, []interface{}) {
+	return `SELECT COUNT(*) FROM article` + q.whereClause(), q.args
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestListQuery(t *testing.T) {
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	before := domain.Position{Title: "It", ID: 4}
+
+	q := listQuery{dialect: dialect.MySQL}
+	q.filter(domain.ArticleFilter{AuthorID: 3, CreatedFrom: from, TitlePrefix: "50%_off' --"})
+	q.page(domain.PageRequest{Before: &before, Num: 10, Sort: domain.Sort{Field: domain.SortTitle, Desc: true}})
+
+	query, args := q.build()
+	assert.Equal(t, "SELECT id, title, content, author_id, updated_at, created_at FROM article"+
+		" WHERE author_id = ? AND created_at >= ? AND title LIKE ? AND (title > ? OR (title = ? AND id > ?))"+
+		" ORDER BY title ASC, id ASC LIMIT ?", query)
+	assert.Equal(t, []interface{}{int64(3), from, `50\%\_off' --%`, "It", "It", int64(4), int64(11)}, args)
+}
+
+func TestListQueryDefaults(t *testing.T) {
+	q := listQuery{dialect: dialect.PostgreSQL}
+	q.page(domain.PageRequest{Num: 5})
+
+	query, args := q.build()
+	assert.Equal(t, "SELECT id, title, content, author_id, updated_at, created_at FROM article ORDER BY created_at ASC, id ASC LIMIT ?", query)
+	assert.Equal(t, []interface{}{int64(6)}, args)
+}
+
+func TestListQueryOffset(t *testing.T) {
+	q := listQuery{dialect: dialect.SQLite}
+	q.filter(domain.ArticleFilter{AuthorID: 3})
+	q.offsetPage(domain.OffsetRequest{Page: 3, PerPage: 20, Sort: domain.Sort{Field: domain.SortUpdatedAt, Desc: true}})
+
+	query, args := q.build()
+	assert.Equal(t, "SELECT id, title, content, author_id, updated_at, created_at FROM article"+
+		" WHERE author_id = ? ORDER BY updated_at DESC, id DESC LIMIT ? OFFSET ?", query)
+	assert.Equal(t, []interface{}{int64(3), int64(20), int64(40)}, args)
+
+	query, args = q.count()
+	assert.Equal(t, "SELECT COUNT(*) FROM article WHERE author_id = ?", query)
+	assert.Equal(t, []interface{}{int64(3)}, args)
+}
@@ -5,25 +5,28 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/angelRaynov/clean-architecture/article/repository"
+	"github.com/angelRaynov/clean-architecture/dialect"
 	"github.com/angelRaynov/clean-architecture/domain"
-	"github.com/labstack/gommon/log"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"time"
 )
 
 type articleRepository struct {
-	DB *sql.DB
+	DB      *sql.DB
+	dialect dialect.Dialect
 }
 
 func (ar *articleRepository) fetch(ctx context.Context, query string, args ...interface{}) (res []domain.Article, err error) {
-	rows, err := ar.DB.QueryContext(ctx, query, args...)
+	rows, err := ar.DB.QueryContext(ctx, ar.dialect.Rebind(query), ar.dialect.Args(args...)...)
 	if err != nil {
-		log.Error(err)
+		logging.FromContext(ctx).Error("query failed", "repository", "article", "err", err)
 		return nil, err
 	}
 
 	defer func() {
 		errRow := rows.Close()
 		if errRow != nil {
-			log.Error(errRow)
+			logging.FromContext(ctx).Error("closing rows failed", "repository", "article", "err", errRow)
 		}
 	}()
 
@@ -41,7 +44,7 @@ func (ar *articleRepository) fetch(ctx context.Context, query string, args ...in
 		)
 
 		if err != nil {
-			log.Error(err)
+			logging.FromContext(ctx).Error("scanning row failed", "repository", "article", "err", err)
 			return nil, err
 		}
 
@@ -52,28 +55,58 @@ func (ar *articleRepository) fetch(ctx context.Context, query string, args ...in
 		result = append(result, t)
 	}
 
+	if err := rows.Err(); err != nil {
+		logging.FromContext(ctx).Error("reading rows failed", "repository", "article", "err", err)
+		return nil, err
+	}
+
 	return result, nil
 }
 
-func (ar *articleRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.Article, nextCursor string, err error) {
-	query := `SELECT id, title, content, author_id, updated_at, created_at 
-			FROM article WHERE created_at > ? ORDER BY created_at LIMIT ?`
-
-	decodedCursor, err := repository.DecodeCursor(cursor)
-	if err != nil && cursor != "" {
-		return nil, "", domain.ErrBadInput
-	}
+// Fetch runs a keyset query: rows are ordered by the sort field and then id,
+// so articles sharing a value are neither skipped nor repeated.
+func (ar *articleRepository) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	q := listQuery{dialect: ar.dialect}
+	q.filter(filter)
+	q.page(page)
 
-	res, err = ar.fetch(ctx, query, decodedCursor, num)
+	query, args := q.build()
+	res, err := ar.fetch(ctx, query, args...)
 	if err != nil {
-		return nil, "", err
+		return nil, domain.PageInfo{}, err
 	}
 
-	if len(res) == int(num) {
-		nextCursor = repository.EncodeCursor(res[len(res)-1].CreatedAt)
+	res, info := repository.Window(res, page)
+	return res, info, nil
+}
+
+func (ar *articleRepository) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	return ar.Fetch(ctx, domain.ArticleFilter{AuthorID: authorID}, page)
+}
+
+func (ar *articleRepository) FetchOffset(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) ([]domain.Article, error) {
+	q := listQuery{dialect: ar.dialect}
+	q.filter(filter)
+	q.offsetPage(page)
+
+	query, args := q.build()
+	return ar.fetch(ctx, query, args...)
+}
+
+func (ar *articleRepository) Count(ctx context.Context, filter domain.ArticleFilter) (int64, error) {
+	q := listQuery{dialect: ar.dialect}
+	q.filter(filter)
+
+	query, args := q.count()
+
+	var total int64
+	err := ar.DB.QueryRowContext(ctx, ar.dialect.Rebind(query), ar.dialect.Args(args...)...).Scan(&total)
+	if err != nil {
+		logging.FromContext(ctx).Error("query failed", "repository", "article", "err", err)
+		return 0, err
 	}
 
-	return res, nextCursor, err
+	return total, nil
 }
 
 func (ar *articleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
@@ -116,15 +149,19 @@ func (ar *articleRepository) GetByTitle(ctx context.Context, title string) (doma
 	return res, err
 }
 
-func (ar *articleRepository) Update(ctx context.Context, a *domain.Article) error {
-	query := `UPDATE article SET title=?, content=?, author_id=? updated_at=? WHERE id = ?`
+func (ar *articleRepository) Update(ctx context.Context, a *domain.Article, version time.Time) error {
+	query := `UPDATE article SET title=?, content=?, author_id=?, updated_at=? WHERE id = ? AND updated_at = ?`
 
-	stmt, err := ar.DB.PrepareContext(ctx, query)
+	stmt, err := ar.DB.PrepareContext(ctx, ar.dialect.Rebind(query))
 	if err != nil {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.ID)
+	res, err := stmt.ExecContext(ctx, ar.dialect.Args(a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.ID, version)...)
+	if ar.dialect.IsUniqueViolation(err) {
+		return domain.ErrConflict
+	}
+
 	if err != nil {
 		return err
 	}
@@ -134,6 +171,10 @@ func (ar *articleRepository) Update(ctx context.Context, a *domain.Article) erro
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return ar.updateMissReason(ctx, a.ID)
+	}
+
 	if rowsAffected != 1 {
 		err = fmt.Errorf("err: rows affected %d", rowsAffected)
 	}
@@ -142,15 +183,42 @@ func (ar *articleRepository) Update(ctx context.Context, a *domain.Article) erro
 
 }
 
+// updateMissReason tells apart an update that matched no row because the article
+// is gone from one that lost the race against a concurrent writer.
+func (ar *articleRepository) updateMissReason(ctx context.Context, id int64) error {
+	_, err := ar.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return domain.ErrPreconditionFailed
+}
+
 func (ar *articleRepository) Store(ctx context.Context, a *domain.Article) error {
-	query := `INSERT article SET title=?, content=?, updated_at=?, created_at=?`
+	query := `INSERT INTO article (title, content, author_id, updated_at, created_at) VALUES (?, ?, ?, ?, ?)`
+	if ar.dialect.ReturningID() {
+		query += ` RETURNING id`
+	}
 
-	stmt, err := ar.DB.PrepareContext(ctx, query)
+	stmt, err := ar.DB.PrepareContext(ctx, ar.dialect.Rebind(query))
 	if err != nil {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.CreatedAt)
+	if ar.dialect.ReturningID() {
+		err = stmt.QueryRowContext(ctx, ar.dialect.Args(a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.CreatedAt)...).Scan(&a.ID)
+		if ar.dialect.IsUniqueViolation(err) {
+			return domain.ErrConflict
+		}
+
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, ar.dialect.Args(a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.CreatedAt)...)
+	if ar.dialect.IsUniqueViolation(err) {
+		return domain.ErrConflict
+	}
+
 	if err != nil {
 		return err
 	}
@@ -168,12 +236,12 @@ func (ar *articleRepository) Store(ctx context.Context, a *domain.Article) error
 func (ar *articleRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM article WHERE id = ?`
 
-	stmt, err := ar.DB.PrepareContext(ctx, query)
+	stmt, err := ar.DB.PrepareContext(ctx, ar.dialect.Rebind(query))
 	if err != nil {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, ar.dialect.Args(id)...)
 	if err != nil {
 		return err
 	}
@@ -183,6 +251,10 @@ func (ar *articleRepository) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
 	if rowsAffected != 1 {
 		err = fmt.Errorf("err: rows affected %d", rowsAffected)
 	}
@@ -191,7 +263,14 @@ func (ar *articleRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func NewArticleRepository(db *sql.DB) domain.ArticleRepository {
+	return NewArticleRepositoryWithDialect(db, dialect.MySQL)
+}
+
+// NewArticleRepositoryWithDialect returns a repository for a PostgreSQL or
+// SQLite database, or for MySQL when d is dialect.MySQL.
+func NewArticleRepositoryWithDialect(db *sql.DB, d dialect.Dialect) domain.ArticleRepository {
 	return &articleRepository{
-		DB: db,
+		DB:      db,
+		dialect: d,
 	}
 }
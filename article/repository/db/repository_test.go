@@ -1,10 +1,15 @@
 package db
 
 import (
+	"bytes"
 	"context"
-	"github.com/angelRaynov/clean-architecture/article/repository"
+	"encoding/json"
+	"errors"
 	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 	"testing"
 	"time"
@@ -32,15 +37,85 @@ func TestArticleRepository_Fetch(t *testing.T) {
 		AddRow(mockArticles[1].ID, mockArticles[1].Title, mockArticles[1].Content,
 			mockArticles[1].Author.ID, mockArticles[1].UpdatedAt, mockArticles[1].CreatedAt)
 
-	query := "SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE created_at > \\? ORDER BY created_at LIMIT \\?"
-	mock.ExpectQuery(query).WillReturnRows(rows)
+	query := "SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE \\(created_at > \\? OR \\(created_at = \\? AND id > \\?\\)\\) ORDER BY created_at ASC, id ASC LIMIT \\?"
+	after := domain.Position{Time: mockArticles[1].CreatedAt, ID: 7}
+	mock.ExpectQuery(query).WithArgs(after.Time, after.Time, after.ID, 2).WillReturnRows(rows)
+	a := NewArticleRepository(db)
+	num := int64(1)
+	list, info, err := a.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{After: &after, Num: num})
+	assert.True(t, info.HasNext)
+	assert.True(t, info.HasPrev)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestArticleRepository_FetchBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "updated_at", "created_at"}).
+		AddRow(2, "title 2", "content 2", 1, now, now).
+		AddRow(1, "title 1", "content 1", 1, now, now)
+
+	query := "SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE \\(created_at < \\? OR \\(created_at = \\? AND id < \\?\\)\\) ORDER BY created_at DESC, id DESC LIMIT \\?"
+	before := domain.Position{Time: now, ID: 3}
+	mock.ExpectQuery(query).WithArgs(now, now, 3, 3).WillReturnRows(rows)
+	a := NewArticleRepository(db)
+
+	list, info, err := a.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Before: &before, Num: 2})
+	require.NoError(t, err)
+	assert.True(t, info.HasNext)
+	assert.False(t, info.HasPrev)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(1), list[0].ID, "the page is in ascending order")
+}
+
+func TestArticleRepository_FetchLogsWithRequestContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo).With("request_id", "req-1", "route", "/articles")
+	ctx := logging.WithContext(context.TODO(), logger)
+
+	mock.ExpectQuery("SELECT (.+) FROM article").WillReturnError(errors.New("connection refused"))
+	a := NewArticleRepository(db)
+
+	_, _, err = a.Fetch(ctx, domain.ArticleFilter{}, domain.PageRequest{Num: 2})
+	assert.Error(t, err)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "/articles", line["route"])
+	assert.Equal(t, "connection refused", line["err"])
+}
+
+func TestArticleRepository_FetchByAuthor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "updated_at", "created_at"}).
+		AddRow(1, "title 1", "content 1", 3, now, now)
+
+	query := "SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE author_id = \\? ORDER BY created_at ASC, id ASC LIMIT \\?"
+	mock.ExpectQuery(query).WithArgs(3, 3).WillReturnRows(rows)
 	a := NewArticleRepository(db)
-	cursor := repository.EncodeCursor(mockArticles[1].CreatedAt)
-	num := int64(2)
-	list, nextCursor, err := a.Fetch(context.TODO(), cursor, num)
-	assert.NotEmpty(t, nextCursor)
+
+	list, info, err := a.FetchByAuthor(context.TODO(), 3, domain.PageRequest{Num: 2})
 	assert.NoError(t, err)
-	assert.Len(t, list, 2)
+	assert.False(t, info.HasNext)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(3), list[0].Author.ID)
 }
 
 func TestArticleRepository_GetByID(t *testing.T) {
@@ -80,8 +155,7 @@ func TestArticleRepository_Store(t *testing.T) {
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
-	//TODO: fix the test
-	query := "INSERT article"
+	query := "INSERT INTO article \\(title, content, author_id, updated_at, created_at\\)"
 	prep := mock.ExpectPrepare(query)
 	prep.ExpectExec().WithArgs(ar.Title, ar.Content, ar.Author.ID, ar.UpdatedAt, ar.CreatedAt).WillReturnResult(sqlmock.NewResult(12, 1))
 	a := NewArticleRepository(db)
@@ -132,6 +206,7 @@ func TestDelete(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	now := time.Now()
+	version := now.Add(-time.Minute)
 	ar := &domain.Article{
 		ID:        12,
 		Title:     "It",
@@ -149,13 +224,64 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-	query := "UPDATE article"
+	query := "UPDATE article SET title=\\?, content=\\?, author_id=\\?, updated_at=\\? WHERE id = \\? AND updated_at = \\?"
 
 	prep := mock.ExpectPrepare(query)
-	prep.ExpectExec().WithArgs(ar.Title, ar.Content, ar.Author.ID, ar.UpdatedAt, ar.ID).WillReturnResult(sqlmock.NewResult(12, 1))
+	prep.ExpectExec().WithArgs(ar.Title, ar.Content, ar.Author.ID, ar.UpdatedAt, ar.ID, version).WillReturnResult(sqlmock.NewResult(12, 1))
 
 	a := NewArticleRepository(db)
 
-	err = a.Update(context.TODO(), ar)
+	err = a.Update(context.TODO(), ar, version)
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+
+func TestUpdateStaleVersion(t *testing.T) {
+	now := time.Now()
+	ar := &domain.Article{
+		ID:        12,
+		Title:     "It",
+		Content:   "Content",
+		UpdatedAt: now,
+		Author: domain.Author{
+			ID: 1,
+		},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	prep := mock.ExpectPrepare("UPDATE article")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "updated_at", "created_at"}).
+		AddRow(12, "It", "Content", 1, now.Add(time.Second), now)
+	mock.ExpectQuery("SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE id = \\?").
+		WillReturnRows(rows)
+
+	a := NewArticleRepository(db)
+
+	err = a.Update(context.TODO(), ar, now.Add(-time.Minute))
+	assert.Equal(t, domain.ErrPreconditionFailed, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMissingArticle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	prep := mock.ExpectPrepare("UPDATE article")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "updated_at", "created_at"})
+	mock.ExpectQuery("SELECT id, title, content, author_id, updated_at, created_at FROM article WHERE id = \\?").
+		WillReturnRows(rows)
+
+	a := NewArticleRepository(db)
+
+	err = a.Update(context.TODO(), &domain.Article{ID: 12}, time.Now())
+	assert.Equal(t, domain.ErrNotFound, err)
+}
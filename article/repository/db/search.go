@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/article/repository"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"strconv"
+	"strings"
+)
+
+const (
+	matchAgainst = `MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)`
+	tsDocument   = `to_tsvector('simple', title || ' ' || content)`
+	tsQuery      = `plainto_tsquery('simple', ?)`
+)
+
+// fullTextExpressions returns the relevance score, rounded to
+// repository.ScoreDecimals, and the match condition for dialects that have
+// native full-text search, with their arguments in that order.
+func fullTextExpressions(d dialect.Dialect, query string) (score string, match string, args []interface{}, ok bool) {
+	decimals := strconv.Itoa(repository.ScoreDecimals)
+	args = []interface{}{query, query}
+	switch d {
+	case dialect.MySQL:
+		return `ROUND(` + matchAgainst + `, ` + decimals + `)`, matchAgainst, args, true
+	case dialect.PostgreSQL:
+		return `ROUND(CAST(ts_rank(` + tsDocument + `, ` + tsQuery + `) AS NUMERIC), ` + decimals + `)`, tsDocument + ` @@ ` + tsQuery, args, true
+	default:
+		return "", "", nil, false
+	}
+}
+
+// likeExpressions serves dialects without native full-text search. Articles
+// match with LIKE, and the score counts term occurrences the way
+// repository.Score does, so results are paged in SQL all the same. Like LIKE,
+// LOWER only folds ASCII letters in SQLite.
+func likeExpressions(d dialect.Dialect, terms []string) (score string, match string, args []interface{}) {
+	scores := make([]string, 0, len(terms))
+	conditions := make([]string, 0, len(terms))
+	matchArgs := make([]interface{}, 0, 2*len(terms))
+	for _, term := range terms {
+		scores = append(scores, "2*"+occurrences("title")+" + "+occurrences("content"))
+		args = append(args, term, term, term, term)
+
+		pattern := "%" + escapeLike(term) + "%"
+		conditions = append(conditions, d.Like("title")+" OR "+d.Like("content"))
+		matchArgs = append(matchArgs, pattern, pattern)
+	}
+
+	return strings.Join(scores, " + "), strings.Join(conditions, " OR "), append(args, matchArgs...)
+}
+
+// occurrences counts the occurrences of a term in column; it takes the term
+// twice.
+func occurrences(column string) string {
+	return `((LENGTH(LOWER(` + column + `)) - LENGTH(REPLACE(LOWER(` + column + `), ?, ''))) / LENGTH(?))`
+}
+
+// Search ranks articles by relevance to the query. Results are ordered by
+// descending score and then by id. Scores are rounded before they are
+// compared, so the score a cursor carries matches the one the next query
+// computes for the same article.
+func (ar *articleRepository) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo, error) {
+	terms := repository.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, domain.PageInfo{}, domain.ErrBadInput
+	}
+
+	scoreExpr, matchExpr, args, ok := fullTextExpressions(ar.dialect, query)
+	if !ok {
+		scoreExpr, matchExpr, args = likeExpressions(ar.dialect, terms)
+	}
+
+	res, err := ar.rankedSearch(ctx, scoreExpr, matchExpr, args, after, num+1)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	res, info := repository.SearchWindow(res, terms, after, num)
+	return res, info, nil
+}
+
+// rankedSearch returns up to limit articles matching matchExpr after the
+// cursor, best scoreExpr first. args holds the arguments of both expressions.
+func (ar *articleRepository) rankedSearch(ctx context.Context, scoreExpr, matchExpr string, args []interface{}, after *domain.SearchPosition, limit int64) ([]domain.ArticleSearchResult, error) {
+	stmt := `SELECT id, title, content, author_id, updated_at, created_at, score FROM (
+			SELECT id, title, content, author_id, updated_at, created_at, ` + scoreExpr + ` AS score
+			FROM article WHERE ` + matchExpr + `) ranked`
+
+	if after != nil {
+		stmt += ` WHERE score < ? OR (score = ? AND id > ?)`
+		args = append(args, after.Score, after.Score, after.ID)
+	}
+
+	stmt += ` ORDER BY score DESC, id LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := ar.DB.QueryContext(ctx, ar.dialect.Rebind(stmt), ar.dialect.Args(args...)...)
+	if err != nil {
+		logging.FromContext(ctx).Error("query failed", "repository", "article", "err", err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logging.FromContext(ctx).Error("closing rows failed", "repository", "article", "err", errRow)
+		}
+	}()
+
+	result := make([]domain.ArticleSearchResult, 0)
+	for rows.Next() {
+		var t domain.ArticleSearchResult
+		err := rows.Scan(
+			&t.Article.ID,
+			&t.Article.Title,
+			&t.Article.Content,
+			&t.Article.Author.ID,
+			&t.Article.UpdatedAt,
+			&t.Article.CreatedAt,
+			&t.Score,
+		)
+
+		if err != nil {
+			logging.FromContext(ctx).Error("scanning row failed", "repository", "article", "err", err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		logging.FromContext(ctx).Error("reading rows failed", "repository", "article", "err", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestArticleRepository_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	columns := []string{"id", "title", "content", "author_id", "updated_at", "created_at", "score"}
+	occurrences := func(column string) string {
+		return regexp.QuoteMeta(`((LENGTH(LOWER(` + column + `)) - LENGTH(REPLACE(LOWER(` + column + `), ?, ''))) / LENGTH(?))`)
+	}
+	query := regexp.QuoteMeta(`SELECT id, title, content, author_id, updated_at, created_at, score FROM (`) + `\s+` +
+		regexp.QuoteMeta(`SELECT id, title, content, author_id, updated_at, created_at, 2*`) + occurrences("title") + ` \+ ` + occurrences("content") + ` AS score\s+` +
+		regexp.QuoteMeta(`FROM article WHERE title LIKE ? ESCAPE '\' OR content LIKE ? ESCAPE '\') ranked`)
+	args := []driver.Value{"roses", "roses", "roses", "roses", "%roses%", "%roses%"}
+	a := NewArticleRepositoryWithDialect(db, dialect.SQLite)
+
+	mock.ExpectQuery(query + regexp.QuoteMeta(` ORDER BY score DESC, id LIMIT ?`)).WithArgs(append(args, 3)...).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, "Roses", "A book about roses", 1, now, now, 3).
+			AddRow(1, "Gardening", "Roses need <sun> and roses need water", 1, now, now, 2).
+			AddRow(3, "Tulips", "Not a single rose here, only roses", 2, now, now, 1))
+	firstPage, info, err := a.Search(context.TODO(), "Roses", nil, 2)
+	assert.NoError(t, err)
+	assert.True(t, info.HasNext)
+	assert.Len(t, firstPage, 2)
+	assert.Equal(t, int64(2), firstPage[0].Article.ID)
+	assert.Equal(t, int64(1), firstPage[1].Article.ID)
+	assert.Equal(t, "<mark>Roses</mark> need &lt;sun&gt; and <mark>roses</mark> need water", firstPage[1].Snippet)
+
+	after := firstPage[1].Position()
+	mock.ExpectQuery(query + regexp.QuoteMeta(` WHERE score < ? OR (score = ? AND id > ?) ORDER BY score DESC, id LIMIT ?`)).
+		WithArgs(append(args, float64(2), float64(2), int64(1), 3)...).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(3, "Tulips", "Not a single rose here, only roses", 2, now, now, 1))
+	secondPage, info, err := a.Search(context.TODO(), "Roses", &after, 2)
+	assert.NoError(t, err)
+	assert.False(t, info.HasNext)
+	assert.True(t, info.HasPrev)
+	assert.Len(t, secondPage, 1)
+	assert.Equal(t, int64(3), secondPage[0].Article.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArticleRepository_SearchFullText(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "updated_at", "created_at", "score"}).
+		AddRow(4, "Roses", "All about roses", 1, now, now, 0.75).
+		AddRow(5, "Tulips", "Roses, and tulips", 1, now, now, 0.5)
+
+	query := "SELECT id, title, content, author_id, updated_at, created_at, score FROM \\(.+ROUND\\(MATCH\\(title, content\\) AGAINST.+, 6\\) AS score.+\\) ranked WHERE score < \\? OR \\(score = \\? AND id > \\?\\) ORDER BY score DESC, id LIMIT \\?"
+	mock.ExpectQuery(query).WithArgs("roses", "roses", 0.9, 0.9, 2, 2).WillReturnRows(rows)
+
+	a := NewArticleRepository(db)
+	res, info, err := a.Search(context.TODO(), "roses", &domain.SearchPosition{Score: 0.9, ID: 2}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, info.HasNext, "the extra row tells there is more")
+	assert.Equal(t, domain.SearchPosition{Score: 0.75, ID: 4}, res[0].Position())
+	assert.Equal(t, "All about <mark>roses</mark>", res[0].Snippet)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
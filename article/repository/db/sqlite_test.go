@@ -0,0 +1,17 @@
+//go:build cgo
+
+package db
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"github.com/angelRaynov/clean-architecture/migration/migrationtest"
+	"testing"
+)
+
+func TestArticleRepository_SQLiteContract(t *testing.T) {
+	repositorytest.ArticleRepository(t, func(t *testing.T) domain.ArticleRepository {
+		return NewArticleRepositoryWithDialect(migrationtest.OpenSQLite(t), dialect.SQLite)
+	})
+}
@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/article/repository"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// articleRepository keeps articles in a map guarded by a RWMutex. Like the SQL
+// repository it only remembers the author id of an article, and titles are
+// unique.
+type articleRepository struct {
+	mu       sync.RWMutex
+	articles map[int64]domain.Article
+	lastID   int64
+}
+
+func NewArticleRepository() domain.ArticleRepository {
+	return &articleRepository{
+		articles: map[int64]domain.Article{},
+	}
+}
+
+// sorted returns the articles matching keep in the order of by, which is the
+// order the pages of Fetch walk through.
+func (ar *articleRepository) sorted(keep func(domain.Article) bool, by domain.Sort) []domain.Article {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	res := make([]domain.Article, 0, len(ar.articles))
+	for _, a := range ar.articles {
+		if keep(a) {
+			res = append(res, a)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return by.Less(by.Position(res[i]), by.Position(res[j]))
+	})
+
+	return res
+}
+
+func (ar *articleRepository) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	by := page.Sort
+	res := ar.sorted(func(a domain.Article) bool {
+		pos := by.Position(a)
+		return matches(filter, a) &&
+			(page.After == nil || by.Less(*page.After, pos)) &&
+			(page.Before == nil || by.Less(pos, *page.Before))
+	}, by)
+
+	if page.Before != nil {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+
+	if int64(len(res)) > page.Num+1 {
+		res = res[:page.Num+1]
+	}
+
+	res, info := repository.Window(res, page)
+	return res, info, nil
+}
+
+func (ar *articleRepository) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	return ar.Fetch(ctx, domain.ArticleFilter{AuthorID: authorID}, page)
+}
+
+func (ar *articleRepository) FetchOffset(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) ([]domain.Article, error) {
+	res := ar.sorted(func(a domain.Article) bool { return matches(filter, a) }, page.Sort)
+
+	offset := page.Offset()
+	if offset >= int64(len(res)) {
+		return []domain.Article{}, nil
+	}
+
+	res = res[offset:]
+	if int64(len(res)) > page.PerPage {
+		res = res[:page.PerPage]
+	}
+
+	return res, nil
+}
+
+func (ar *articleRepository) Count(ctx context.Context, filter domain.ArticleFilter) (int64, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	var total int64
+	for _, a := range ar.articles {
+		if matches(filter, a) {
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// matches reports whether a passes filter.
+func matches(filter domain.ArticleFilter, a domain.Article) bool {
+	inRange := func(t, from, to time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+
+	return (filter.AuthorID == 0 || a.Author.ID == filter.AuthorID) &&
+		inRange(a.CreatedAt, filter.CreatedFrom, filter.CreatedTo) &&
+		inRange(a.UpdatedAt, filter.UpdatedFrom, filter.UpdatedTo) &&
+		strings.HasPrefix(strings.ToLower(a.Title), strings.ToLower(filter.TitlePrefix))
+}
+
+func (ar *articleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	a, ok := ar.articles[id]
+	if !ok {
+		return domain.Article{}, domain.ErrNotFound
+	}
+
+	return a, nil
+}
+
+func (ar *articleRepository) GetByTitle(ctx context.Context, title string) (domain.Article, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	for _, a := range ar.articles {
+		if a.Title == title {
+			return a, nil
+		}
+	}
+
+	return domain.Article{}, domain.ErrNotFound
+}
+
+func (ar *articleRepository) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo, error) {
+	terms := repository.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, domain.PageInfo{}, domain.ErrBadInput
+	}
+
+	all := ar.sorted(func(domain.Article) bool { return true }, domain.Sort{})
+
+	res, info := repository.RankArticles(all, terms, after, num)
+	return res, info, nil
+}
+
+func (ar *articleRepository) Update(ctx context.Context, a *domain.Article, version time.Time) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	existing, ok := ar.articles[a.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	if !existing.UpdatedAt.Equal(version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	if ar.titleTaken(a.Title, a.ID) {
+		return domain.ErrConflict
+	}
+
+	existing.Title = a.Title
+	existing.Content = a.Content
+	existing.Author = domain.Author{ID: a.Author.ID}
+	existing.UpdatedAt = a.UpdatedAt
+	ar.articles[a.ID] = existing
+
+	return nil
+}
+
+func (ar *articleRepository) Store(ctx context.Context, a *domain.Article) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.titleTaken(a.Title, 0) {
+		return domain.ErrConflict
+	}
+
+	ar.lastID++
+	a.ID = ar.lastID
+
+	stored := *a
+	stored.Author = domain.Author{ID: a.Author.ID}
+	ar.articles[a.ID] = stored
+
+	return nil
+}
+
+func (ar *articleRepository) Delete(ctx context.Context, id int64) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if _, ok := ar.articles[id]; !ok {
+		return domain.ErrNotFound
+	}
+
+	delete(ar.articles, id)
+
+	return nil
+}
+
+// titleTaken must be called with the lock held.
+func (ar *articleRepository) titleTaken(title string, exceptID int64) bool {
+	for id, a := range ar.articles {
+		if a.Title == title && id != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
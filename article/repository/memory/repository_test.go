@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArticleRepository_ConcurrentStore(t *testing.T) {
+	a := NewArticleRepository()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := a.Store(context.TODO(), &domain.Article{
+				Title:     fmt.Sprintf("title %d", i),
+				CreatedAt: now.Add(time.Duration(i) * time.Millisecond),
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	list, _, err := a.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Num: 100})
+	assert.NoError(t, err)
+	assert.Len(t, list, 50)
+
+	ids := map[int64]bool{}
+	for _, article := range list {
+		ids[article.ID] = true
+	}
+	assert.Len(t, ids, 50)
+}
+
+func TestArticleRepository_Contract(t *testing.T) {
+	repositorytest.ArticleRepository(t, func(t *testing.T) domain.ArticleRepository {
+		return NewArticleRepository()
+	})
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "github.com/angelRaynov/clean-architecture/domain"
+
+// Window cuts the page out of res, the items found past the cursor of
+// page in the direction of travel, at most page.Num+1 of them: the extra one
+// only tells there is more. For page.Before res is in descending order and
+// the page is put back in ascending order.
+func Window[T any](res []T, page domain.PageRequest) ([]T, domain.PageInfo) {
+	more := int64(len(res)) > page.Num
+	if more {
+		res = res[:page.Num]
+	}
+
+	if page.Before == nil {
+		return res, domain.PageInfo{HasNext: more, HasPrev: page.After != nil}
+	}
+
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res, domain.PageInfo{HasNext: true, HasPrev: more}
+}
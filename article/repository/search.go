@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	snippetRadius = 80
+	markOpen      = "<mark>"
+	markClose     = "</mark>"
+)
+
+// ScoreDecimals is the precision scores are rounded to before they are
+// returned or compared. Cursors carry the rounded score, so comparing it with
+// the rounded score of the next query is exact even when the database
+// computes relevance in floating point.
+const ScoreDecimals = 6
+
+// SearchTerms splits a free-text query into lower-cased terms.
+func SearchTerms(query string) []string {
+	fields := strings.Fields(query)
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		terms = append(terms, strings.ToLower(f))
+	}
+
+	return terms
+}
+
+// RoundScore rounds score to ScoreDecimals decimals.
+func RoundScore(score float64) float64 {
+	scale := math.Pow10(ScoreDecimals)
+	return math.Round(score*scale) / scale
+}
+
+// RankArticles orders candidate matches by Score, highest first and then by id,
+// and returns the num results that follow after. It backs search for storage
+// without a full-text index.
+func RankArticles(list []domain.Article, terms []string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo) {
+	res := make([]domain.ArticleSearchResult, 0, len(list))
+	for _, a := range list {
+		score := RoundScore(Score(a, terms))
+		if score == 0 {
+			continue
+		}
+
+		if after != nil && !(score < after.Score || (score == after.Score && a.ID > after.ID)) {
+			continue
+		}
+
+		res = append(res, domain.ArticleSearchResult{Article: a, Score: score})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Article.ID < res[j].Article.ID
+	})
+
+	if int64(len(res)) > num+1 {
+		res = res[:num+1]
+	}
+
+	return SearchWindow(res, terms, after, num)
+}
+
+// SearchWindow cuts the page out of res, the results found after the cursor,
+// at most num+1 of them: the extra one only tells there is more. The results
+// of the page get their snippets.
+func SearchWindow(res []domain.ArticleSearchResult, terms []string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo) {
+	more := int64(len(res)) > num
+	if more {
+		res = res[:num]
+	}
+
+	for i := range res {
+		res[i].Snippet = Snippet(res[i].Article.Content, terms)
+	}
+
+	return res, domain.PageInfo{HasNext: more, HasPrev: after != nil}
+}
+
+// Score ranks an article for backends without a full-text index: every term
+// occurrence counts, and occurrences in the title weigh twice as much.
+func Score(a domain.Article, terms []string) float64 {
+	title := strings.ToLower(a.Title)
+	content := strings.ToLower(a.Content)
+
+	var score float64
+	for _, term := range terms {
+		score += 2*float64(strings.Count(title, term)) + float64(strings.Count(content, term))
+	}
+
+	return score
+}
+
+// Snippet cuts the content around the first term occurrence and wraps every
+// occurrence in <mark> tags. The rest of the text is HTML-escaped.
+func Snippet(content string, terms []string) string {
+	text := []rune(content)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	type match struct{ start, end int }
+	var matches []match
+	for i := 0; i < len(lower); {
+		end := 0
+		for _, term := range terms {
+			if n := prefixLen(lower[i:], []rune(term)); n > end {
+				end = n
+			}
+		}
+
+		if end == 0 {
+			i++
+			continue
+		}
+
+		matches = append(matches, match{i, i + end})
+		i += end
+	}
+
+	from, to := 0, len(text)
+	if len(matches) > 0 {
+		from = matches[0].start - snippetRadius
+		to = matches[0].end + snippetRadius
+	} else {
+		to = 2 * snippetRadius
+	}
+
+	if from < 0 {
+		from = 0
+	}
+
+	if to > len(text) {
+		to = len(text)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+
+	pos := from
+	for _, m := range matches {
+		if m.start >= to {
+			break
+		}
+
+		b.WriteString(html.EscapeString(string(text[pos:m.start])))
+		b.WriteString(markOpen)
+		b.WriteString(html.EscapeString(string(text[m.start:m.end])))
+		b.WriteString(markClose)
+		pos = m.end
+	}
+
+	if pos < to {
+		b.WriteString(html.EscapeString(string(text[pos:to])))
+	}
+
+	if to < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+func prefixLen(text, term []rune) int {
+	if len(term) == 0 || len(term) > len(text) {
+		return 0
+	}
+
+	for i, r := range term {
+		if text[i] != r {
+			return 0
+		}
+	}
+
+	return len(term)
+}
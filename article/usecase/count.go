@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/cache"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCountTTL is how long article counts are reused unless configured.
+const DefaultCountTTL = 5 * time.Second
+
+// maxCounts bounds the number of filters counts are kept for. Filters come
+// from clients, so the least recently used counts make room for new ones.
+const maxCounts = 1000
+
+// countCache remembers for ttl how many articles matched a filter, so walking
+// through numbered pages does not count the table on every request. Writes
+// made through the use case forget every count; writes made by other replicas
+// show up once the counts expire. A zero ttl disables the cache.
+type countCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	counts *cache.LRU
+}
+
+func newCountCache(ttl time.Duration) *countCache {
+	return &countCache{
+		ttl:    ttl,
+		counts: cache.NewLRU(maxCounts),
+	}
+}
+
+func (c *countCache) get(filter domain.ArticleFilter) (int64, bool) {
+	if c.ttl <= 0 {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The LRU never fails.
+	data, ok, _ := c.counts.Get(context.Background(), countKey(filter))
+	if !ok {
+		return 0, false
+	}
+
+	total, err := strconv.ParseInt(string(data), 10, 64)
+	return total, err == nil
+}
+
+func (c *countCache) set(filter domain.ArticleFilter, total int64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.counts.Set(context.Background(), countKey(filter), strconv.AppendInt(nil, total, 10), c.ttl)
+}
+
+// forget drops every count.
+func (c *countCache) forget() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts = cache.NewLRU(maxCounts)
+}
+
+// countKey identifies filter. Times are compared as instants, whatever their
+// location, and the title prefix ignores case like the filter does.
+func countKey(filter domain.ArticleFilter) string {
+	instant := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.UnixNano()
+	}
+
+	return fmt.Sprintf("%d|%d|%d|%d|%d|%s",
+		filter.AuthorID,
+		instant(filter.CreatedFrom),
+		instant(filter.CreatedTo),
+		instant(filter.UpdatedFrom),
+		instant(filter.UpdatedTo),
+		strings.ToLower(filter.TitlePrefix),
+	)
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCountCacheIsBounded(t *testing.T) {
+	c := newCountCache(time.Minute)
+	for i := 0; i <= maxCounts; i++ {
+		c.set(domain.ArticleFilter{AuthorID: int64(i)}, int64(i))
+	}
+
+	_, ok := c.get(domain.ArticleFilter{AuthorID: 0})
+	assert.False(t, ok, "the oldest count makes room")
+
+	total, ok := c.get(domain.ArticleFilter{AuthorID: maxCounts})
+	assert.True(t, ok)
+	assert.Equal(t, int64(maxCounts), total)
+	assert.Equal(t, maxCounts, c.counts.Len())
+
+	c.forget()
+	_, ok = c.get(domain.ArticleFilter{AuthorID: maxCounts})
+	assert.False(t, ok)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxAuthorBatch bounds the ids of a single GetByIDs call, keeping its IN list
+// well under the placeholder limits of every supported database.
+const maxAuthorBatch = 500
+
+// authorLoader collects the authors a list of articles refers to and loads
+// them in batches, in the manner of a dataloader: every id is looked up once,
+// and ids are sent to the repository maxBatch at a time instead of one query
+// per author.
+type authorLoader struct {
+	repo     domain.AuthorRepository
+	maxBatch int
+
+	ids  []int64
+	seen map[int64]struct{}
+}
+
+func newAuthorLoader(repo domain.AuthorRepository, maxBatch int) *authorLoader {
+	return &authorLoader{
+		repo:     repo,
+		maxBatch: maxBatch,
+		seen:     map[int64]struct{}{},
+	}
+}
+
+// add queues id for the next load. Repeated ids are loaded once.
+func (l *authorLoader) add(id int64) {
+	if _, ok := l.seen[id]; ok {
+		return
+	}
+
+	l.seen[id] = struct{}{}
+	l.ids = append(l.ids, id)
+}
+
+// load looks up the queued ids, running the batches concurrently. It returns
+// the authors found and the queued ids that matched none, in the order they
+// were added. A failing batch fails the whole load.
+func (l *authorLoader) load(ctx context.Context) (map[int64]domain.Author, []int64, error) {
+	var batches [][]int64
+	for ids := l.ids; len(ids) > 0; {
+		n := l.maxBatch
+		if n > len(ids) {
+			n = len(ids)
+		}
+		batches, ids = append(batches, ids[:n]), ids[n:]
+	}
+
+	results := make([]map[int64]domain.Author, len(batches))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, batch := range batches {
+		i, batch := i, batch
+		g.Go(func() error {
+			ctx, span := tracer().Start(gctx, "articleUseCase.fillAuthorDetails.GetAuthors", trace.WithAttributes(attribute.Int("authors", len(batch))))
+			defer span.End()
+
+			res, err := l.repo.GetByIDs(ctx, batch)
+			if err != nil {
+				span.RecordError(err)
+				return err
+			}
+
+			results[i] = res
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	authors := make(map[int64]domain.Author, len(l.ids))
+	for _, res := range results {
+		for id, au := range res {
+			authors[id] = au
+		}
+	}
+
+	var missing []int64
+	for _, id := range l.ids {
+		if _, ok := authors[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	return authors, missing, nil
+}
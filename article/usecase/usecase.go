@@ -2,75 +2,301 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/angelRaynov/clean-architecture/domain"
-	"github.com/labstack/gommon/log"
-	"golang.org/x/sync/errgroup"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"math"
 	"time"
 )
 
+// tracer is looked up on every use so it follows the installed provider.
+func tracer() trace.Tracer {
+	return otel.Tracer("github.com/angelRaynov/clean-architecture/article/usecase")
+}
+
 type articleUseCase struct {
 	articleRepo    domain.ArticleRepository
 	authorRepo     domain.AuthorRepository
 	contextTimeout time.Duration
+	pageSize       domain.PageSize
+	counts         *countCache
 }
 
 func NewArticleUseCase(a domain.ArticleRepository, ar domain.AuthorRepository, timeout time.Duration) domain.ArticleUseCase {
+	return NewArticleUseCaseWithPageSize(a, ar, timeout, domain.DefaultPageSize)
+}
+
+// NewArticleUseCaseWithPageSize returns a use case whose lists hold
+// pageSize.Default articles unless asked otherwise, and refuse to hold more
+// than pageSize.Max.
+func NewArticleUseCaseWithPageSize(a domain.ArticleRepository, ar domain.AuthorRepository, timeout time.Duration, pageSize domain.PageSize) domain.ArticleUseCase {
+	return NewArticleUseCaseWithPaging(a, ar, timeout, pageSize, DefaultCountTTL)
+}
+
+// NewArticleUseCaseWithPaging is NewArticleUseCaseWithPageSize with the
+// totals of numbered pages reused for countTTL; zero counts on every page.
+func NewArticleUseCaseWithPaging(a domain.ArticleRepository, ar domain.AuthorRepository, timeout time.Duration, pageSize domain.PageSize, countTTL time.Duration) domain.ArticleUseCase {
 	return &articleUseCase{
 		articleRepo:    a,
 		authorRepo:     ar,
 		contextTimeout: timeout,
+		pageSize:       pageSize,
+		counts:         newCountCache(countTTL),
 	}
 }
 
-func (a articleUseCase) Fetch(ctx context.Context, cursor string, num int64) ([]domain.Article, string, error) {
-	if num == 0 {
-		num = 10
+func (a articleUseCase) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	if err := checkRead(ctx); err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	page, err := a.resolvePage(page)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	if err = validateFilter(filter); err != nil {
+		return nil, domain.PageInfo{}, err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
 	defer cancel()
 
-	res, nextCursor, err := a.articleRepo.Fetch(ctx, cursor, num)
+	res, info, err := a.articleRepo.Fetch(ctx, filter, page)
 	if err != nil {
-		return nil, "", err
+		return nil, domain.PageInfo{}, err
 	}
 
 	res, err = a.fillAuthorDetails(ctx, res)
 	if err != nil {
-		nextCursor = ""
+		info = domain.PageInfo{}
 	}
 
-	return res, nextCursor, err
+	return res, info, err
 }
 
-func (a articleUseCase) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+// FetchByAuthor lists the articles of a single author. The author is looked up
+// once and shared by every article in the page.
+func (a articleUseCase) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	if err := checkRead(ctx); err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	page, err := a.resolvePage(page)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
 	defer cancel()
 
-	res, err := a.articleRepo.GetByID(ctx, id)
+	author, err := a.authorRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	res, info, err := a.articleRepo.FetchByAuthor(ctx, authorID, page)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	for i := range res {
+		res[i].Author = author
+	}
+
+	return res, info, nil
+}
+
+// FetchPage returns the articles of a numbered page and how many articles
+// match filter. The count is reused for a few seconds, so the total may lag
+// behind writes made by other replicas.
+func (a articleUseCase) FetchPage(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) ([]domain.Article, int64, error) {
+	if err := checkRead(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	page, err := a.resolveOffset(page)
 	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	total, ok := a.counts.get(filter)
+	if !ok {
+		total, err = a.articleRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		a.counts.set(filter, total)
+	}
+
+	res, err := a.articleRepo.FetchOffset(ctx, filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res, err = a.fillAuthorDetails(ctx, res)
+	return res, total, err
+}
+
+// resolvePage applies the page size limits and the default sort to page.
+func (a articleUseCase) resolvePage(page domain.PageRequest) (domain.PageRequest, error) {
+	if page.After != nil && page.Before != nil {
+		return page, domain.NewError(domain.CodeBadInput, "a page cannot be both after and before a cursor")
+	}
+
+	sort, err := resolveSort(page.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	num, err := a.pageSize.Resolve(page.Num)
+	if err != nil {
+		return page, err
+	}
+
+	page.Sort = sort
+	page.Num = num
+	return page, nil
+}
+
+// resolveOffset is resolvePage for numbered pages, which start at 1.
+func (a articleUseCase) resolveOffset(page domain.OffsetRequest) (domain.OffsetRequest, error) {
+	sort, err := resolveSort(page.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	perPage, err := a.pageSize.Resolve(page.PerPage)
+	if err != nil {
+		return page, err
+	}
+
+	if page.Page == 0 {
+		page.Page = 1
+	}
+
+	// The offset of a page past math.MaxInt64/perPage would overflow.
+	if page.Page < 0 || page.Page > math.MaxInt64/perPage {
+		return page, domain.NewError(domain.CodeBadInput, "page is out of range")
+	}
+
+	page.Sort = sort
+	page.PerPage = perPage
+	return page, nil
+}
+
+func resolveSort(s domain.Sort) (domain.Sort, error) {
+	s = s.Normalize()
+	if !s.Field.Valid() {
+		return s, domain.NewError(domain.CodeBadInput, fmt.Sprintf("articles cannot be sorted by %q", s.Field))
+	}
+
+	return s, nil
+}
+
+// validateFilter rejects date ranges that end before they start.
+func validateFilter(filter domain.ArticleFilter) error {
+	var fields []domain.FieldError
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		fields = append(fields, domain.FieldError{Field: "created_to", Message: "must be after created_from"})
+	}
+	if !filter.UpdatedFrom.IsZero() && !filter.UpdatedTo.IsZero() && !filter.UpdatedFrom.Before(filter.UpdatedTo) {
+		fields = append(fields, domain.FieldError{Field: "updated_to", Message: "must be after updated_from"})
+	}
+
+	if len(fields) > 0 {
+		return domain.NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+func (a articleUseCase) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+	if err := checkRead(ctx); err != nil {
 		return domain.Article{}, err
 	}
 
-	resAuthor, err := a.authorRepo.GetByID(ctx, res.Author.ID)
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	res, err := a.articleRepo.GetByID(ctx, id)
 	if err != nil {
 		return domain.Article{}, err
 	}
 
-	res.Author = resAuthor
-	return res, nil
+	return a.withAuthor(ctx, res)
 }
 
-func (a articleUseCase) Update(ctx context.Context, ar *domain.Article) error {
+// Update replaces the stored article with ar. Only its author or an admin may
+// do so. A non-zero version must match the stored updated_at, otherwise
+// domain.ErrPreconditionFailed is returned; a zero version skips that check but
+// still guards against concurrent writers.
+func (a articleUseCase) Update(ctx context.Context, ar *domain.Article, version time.Time) error {
 	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
 
 	defer cancel()
 
-	ar.UpdatedAt = time.Now()
-	return a.articleRepo.Update(ctx, ar)
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	existingArticle, err := a.articleRepo.GetByID(ctx, ar.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = authorize(caller, existingArticle); err != nil {
+		return err
+	}
+
+	if !version.IsZero() && !existingArticle.UpdatedAt.Equal(version) {
+		return domain.ErrPreconditionFailed
+	}
+
+	if ar.Title != existingArticle.Title {
+		sameTitle, err := a.articleRepo.GetByTitle(ctx, ar.Title)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		if err == nil && sameTitle.ID != ar.ID {
+			return domain.ErrConflict
+		}
+	}
+
+	// Only admins may hand an article over to another author.
+	if ar.Author.ID == 0 || !caller.IsAdmin() {
+		ar.Author = existingArticle.Author
+	}
+
+	ar.CreatedAt = existingArticle.CreatedAt
+	ar.UpdatedAt = nextVersion(existingArticle.UpdatedAt)
+
+	if err = a.articleRepo.Update(ctx, ar, existingArticle.UpdatedAt); err != nil {
+		return err
+	}
+
+	a.counts.forget()
+	return nil
 }
 
 func (a articleUseCase) GetByTitle(ctx context.Context, title string) (domain.Article, error) {
+	if err := checkRead(ctx); err != nil {
+		return domain.Article{}, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
 
 	defer cancel()
@@ -80,27 +306,98 @@ func (a articleUseCase) GetByTitle(ctx context.Context, title string) (domain.Ar
 		return domain.Article{}, err
 	}
 
-	resAuthor, err := a.authorRepo.GetByID(ctx, res.Author.ID)
+	return a.withAuthor(ctx, res)
+}
+
+// withAuthor fills in the author of ar. Like fillAuthorDetails it does not
+// fail when the author was deleted, but flags the article with AuthorMissing.
+func (a articleUseCase) withAuthor(ctx context.Context, ar domain.Article) (domain.Article, error) {
+	au, err := a.authorRepo.GetByID(ctx, ar.Author.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		logging.FromContext(ctx).Warn("article refers to a missing author", "article_id", ar.ID, "author_id", ar.Author.ID)
+		ar.AuthorMissing = true
+		return ar, nil
+	}
 	if err != nil {
 		return domain.Article{}, err
 	}
 
-	res.Author = resAuthor
-	return res, nil
+	ar.Author = au
+	return ar, nil
+}
+
+func (a articleUseCase) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo, error) {
+	if err := checkRead(ctx); err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	num, err := a.pageSize.Resolve(num)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	res, info, err := a.articleRepo.Search(ctx, query, after, num)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	articles := make([]domain.Article, len(res))
+	for i := range res {
+		articles[i] = res[i].Article
+	}
+
+	articles, err = a.fillAuthorDetails(ctx, articles)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	for i := range res {
+		res[i].Article = articles[i]
+	}
+
+	return res, info, nil
 }
 
+// Store saves a new article written by the caller. Callers that are not an
+// author must be admins, who may then name any author.
 func (a articleUseCase) Store(ctx context.Context, article *domain.Article) error {
 	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
 
 	defer cancel()
 
-	existingArticle, _ := a.GetByTitle(ctx, article.Title)
-	if existingArticle != (domain.Article{}) {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case caller.AuthorID != 0:
+		article.Author = domain.Author{ID: caller.AuthorID}
+	case !caller.IsAdmin():
+		return domain.ErrForbidden
+	}
+
+	_, err = a.articleRepo.GetByTitle(ctx, article.Title)
+	if err == nil {
 		return domain.ErrConflict
 	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	article.CreatedAt = now
+	article.UpdatedAt = now
+
+	if err = a.articleRepo.Store(ctx, article); err != nil {
+		return err
+	}
 
-	err := a.articleRepo.Store(ctx, article)
-	return err
+	a.counts.forget()
+	return nil
 }
 
 func (a articleUseCase) Delete(ctx context.Context, id int64) error {
@@ -108,6 +405,11 @@ func (a articleUseCase) Delete(ctx context.Context, id int64) error {
 
 	defer cancel()
 
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
 	existingArticle, err := a.articleRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
@@ -117,54 +419,103 @@ func (a articleUseCase) Delete(ctx context.Context, id int64) error {
 		return domain.ErrNotFound
 	}
 
-	return a.articleRepo.Delete(ctx, id)
-}
+	if err = authorize(caller, existingArticle); err != nil {
+		return err
+	}
 
-func (a *articleUseCase) fillAuthorDetails(c context.Context, data []domain.Article) ([]domain.Article, error) {
-	g, ctx := errgroup.WithContext(c)
+	if err = a.articleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
 
-	mapAuthors := map[int64]domain.Author{}
+	a.counts.forget()
+	return nil
+}
 
-	for _, article := range data {
-		mapAuthors[article.Author.ID] = domain.Author{}
-	}
-
-	chanAuthor := make(chan domain.Author)
-	for authorID := range mapAuthors {
-		authorID := authorID
-		g.Go(func() error {
-			res, err := a.authorRepo.GetByID(ctx, authorID)
-			if err != nil {
-				return err
-			}
-			chanAuthor <- res
-			return nil
-		})
-	}
-	go func() {
-		err := g.Wait()
+// fillAuthorDetails replaces the author ids of data with the authors, loaded
+// in batches. Articles whose author no longer exists keep the bare id and are
+// flagged with AuthorMissing; they are logged and recorded on the span instead
+// of failing the whole list.
+func (a *articleUseCase) fillAuthorDetails(c context.Context, data []domain.Article) (_ []domain.Article, err error) {
+	c, span := tracer().Start(c, "articleUseCase.fillAuthorDetails", trace.WithAttributes(attribute.Int("articles", len(data))))
+	defer func() {
 		if err != nil {
-			log.Error(err)
-			return
+			span.RecordError(err)
 		}
-		close(chanAuthor)
+		span.End()
 	}()
 
-	for author := range chanAuthor {
-		if author != (domain.Author{}) {
-			mapAuthors[author.ID] = author
-		}
+	loader := newAuthorLoader(a.authorRepo, maxAuthorBatch)
+	for _, article := range data {
+		loader.add(article.Author.ID)
 	}
 
-	if err := g.Wait(); err != nil {
+	authors, missing, err := loader.load(c)
+	if err != nil {
+		logging.FromContext(c).Error("looking up authors failed", "err", err)
 		return nil, err
 	}
 
+	if len(missing) > 0 {
+		span.SetAttributes(attribute.Int64Slice("authors.missing", missing))
+		logging.FromContext(c).Warn("articles refer to missing authors", "author_ids", missing)
+	}
+
 	for index, item := range data {
-		if a, ok := mapAuthors[item.Author.ID]; ok {
-			data[index].Author = a
+		au, ok := authors[item.Author.ID]
+		if !ok {
+			data[index].AuthorMissing = true
+			continue
 		}
+		data[index].Author = au
 	}
 
 	return data, nil
 }
+
+// callerFrom returns the authenticated caller of a write. Anonymous requests
+// get domain.ErrUnauthorized, callers without the write scope
+// domain.ErrForbidden.
+func callerFrom(ctx context.Context) (domain.Identity, error) {
+	caller, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return domain.Identity{}, domain.ErrUnauthorized
+	}
+
+	if !caller.Allows(domain.ScopeWriteArticles) {
+		return domain.Identity{}, domain.ErrForbidden
+	}
+
+	return caller, nil
+}
+
+// checkRead lets anonymous callers read articles, since reads are public, but
+// holds callers with limited scopes, such as API keys, to the read scope.
+func checkRead(ctx context.Context) error {
+	caller, ok := domain.IdentityFromContext(ctx)
+	if ok && !caller.Allows(domain.ScopeReadArticles) {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// authorize lets the author of ar and admins change it.
+func authorize(caller domain.Identity, ar domain.Article) error {
+	if caller.IsAdmin() || (caller.AuthorID != 0 && caller.AuthorID == ar.Author.ID) {
+		return nil
+	}
+
+	return domain.ErrForbidden
+}
+
+// nextVersion returns the updated_at for a new revision. It is truncated to the
+// second so it survives a DATETIME column unchanged and always moves past the
+// previous one, keeping it usable as an ETag.
+func nextVersion(prev time.Time) time.Time {
+	next := time.Now().Truncate(time.Second)
+	if !next.After(prev) {
+		next = prev.Truncate(time.Second).Add(time.Second)
+	}
+
+	return next
+}
@@ -7,10 +7,20 @@ import (
 	"github.com/angelRaynov/clean-architecture/domain/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"math"
 	"testing"
 	"time"
 )
 
+func asAuthor(id int64) context.Context {
+	return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "author", AuthorID: id})
+}
+
+func asAdmin() context.Context {
+	return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "admin", Roles: []string{domain.RoleAdmin}})
+}
+
 func TestArticleUseCase_Fetch(t *testing.T) {
 	mockArticleRepo := new(mocks.ArticleRepository)
 	mockArticle := domain.Article{
@@ -22,22 +32,19 @@ func TestArticleUseCase_Fetch(t *testing.T) {
 	mockListArticle = append(mockListArticle, mockArticle)
 
 	t.Run("success", func(t *testing.T) {
-		mockArticleRepo.On("Fetch", mock.Anything, mock.AnythingOfType("string"),
-			mock.AnythingOfType("int64")).Return(mockListArticle, "next-cursor", nil).Once()
+		mockArticleRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, mock.AnythingOfType("domain.PageRequest")).
+			Return(mockListArticle, domain.PageInfo{HasNext: true}, nil).Once()
 		mockAuthor := domain.Author{
 			ID: 1,
 			Name: "King",
 		}
 
 		mockAuthorRepo := new(mocks.AuthorRepository)
-		mockAuthorRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockAuthor, nil)
+		mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{0}).Return(map[int64]domain.Author{0: mockAuthor}, nil)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second * 2)
 		num := int64(1)
-		cursor := "12"
-		list, nextCursor, err := u.Fetch(context.TODO(), cursor, num)
-		cursorExpected := "next-cursor"
-		assert.Equal(t, cursorExpected, nextCursor)
-		assert.NotEmpty(t, nextCursor)
+		list, info, err := u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Num: num})
+		assert.True(t, info.HasNext)
 		assert.NoError(t, err)
 		assert.Len(t, list, len(mockListArticle))
 
@@ -45,15 +52,14 @@ func TestArticleUseCase_Fetch(t *testing.T) {
 		mockAuthorRepo.AssertExpectations(t)
 
 		t.Run("error", func(t *testing.T) {
-			mockArticleRepo.On("Fetch", mock.Anything, mock.AnythingOfType("string"),
-				mock.AnythingOfType("int64")).Return(nil, "", errors.New("unexpected error")).Once()
+			mockArticleRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, mock.AnythingOfType("domain.PageRequest")).
+				Return(nil, domain.PageInfo{}, errors.New("unexpected error")).Once()
 			mockAuthorRepo = new(mocks.AuthorRepository)
 			u = NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second * 2)
 			num = int64(1)
-			cursor = "12"
-			list, nextCursor, err = u.Fetch(context.TODO(), cursor, num)
+			list, info, err = u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Num: num})
 
-			assert.Empty(t, nextCursor)
+			assert.False(t, info.HasNext)
 			assert.Error(t, err)
 			assert.Len(t, list, 0)
 			mockArticleRepo.AssertExpectations(t)
@@ -62,6 +68,231 @@ func TestArticleUseCase_Fetch(t *testing.T) {
 	})
 }
 
+func TestArticleUseCase_PageSize(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockArticleRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, domain.PageRequest{Num: 5, Sort: domain.Sort{Field: domain.SortCreatedAt}}).Return([]domain.Article{}, domain.PageInfo{}, nil).Once()
+	mockArticleRepo.On("Search", mock.Anything, "roses", (*domain.SearchPosition)(nil), int64(20)).Return([]domain.ArticleSearchResult{}, domain.PageInfo{}, nil).Once()
+	u := NewArticleUseCaseWithPageSize(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2, domain.PageSize{Default: 5, Max: 20})
+
+	_, _, err := u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{})
+	require.NoError(t, err, "zero asks for the default size")
+
+	_, _, err = u.Search(context.TODO(), "roses", nil, 20)
+	require.NoError(t, err)
+
+	for _, num := range []int64{-1, 21} {
+		_, _, err = u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Num: num})
+		assert.True(t, errors.Is(err, domain.ErrBadInput), "num %d", num)
+
+		_, _, err = u.FetchByAuthor(context.TODO(), 1, domain.PageRequest{Num: num})
+		assert.True(t, errors.Is(err, domain.ErrBadInput), "num %d", num)
+	}
+
+	pos := domain.Position{ID: 1}
+	_, _, err = u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{After: &pos, Before: &pos})
+	assert.True(t, errors.Is(err, domain.ErrBadInput))
+
+	mockArticleRepo.AssertExpectations(t)
+}
+
+func TestArticleUseCase_FetchOptions(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+	_, _, err := u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Sort: domain.Sort{Field: "content"}})
+	assert.True(t, errors.Is(err, domain.ErrBadInput))
+
+	day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	_, _, err = u.Fetch(context.TODO(), domain.ArticleFilter{CreatedFrom: day, CreatedTo: day, UpdatedFrom: day, UpdatedTo: day.AddDate(0, 0, -1)}, domain.PageRequest{})
+	var derr *domain.Error
+	require.True(t, errors.As(err, &derr))
+	assert.Equal(t, domain.CodeValidation, derr.Code)
+	assert.Len(t, derr.Fields, 2)
+
+	mockArticleRepo.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestArticleUseCase_FetchPage(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	filter := domain.ArticleFilter{AuthorID: 1}
+	page := domain.OffsetRequest{Page: 2, PerPage: 10, Sort: domain.Sort{Field: domain.SortCreatedAt}}
+	article := domain.Article{ID: 4, Author: domain.Author{ID: 1}}
+
+	mockArticleRepo.On("Count", mock.Anything, filter).Return(int64(12), nil).Twice()
+	mockArticleRepo.On("FetchOffset", mock.Anything, filter, page).Return([]domain.Article{article}, nil)
+	mockArticleRepo.On("GetByID", mock.Anything, int64(4)).Return(article, nil).Once()
+	mockArticleRepo.On("Delete", mock.Anything, int64(4)).Return(nil).Once()
+	mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{1}).Return(map[int64]domain.Author{1: {ID: 1, Name: "King"}}, nil)
+	u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+	for i := 0; i < 2; i++ {
+		list, total, err := u.FetchPage(context.TODO(), filter, domain.OffsetRequest{Page: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), total, "the count is reused")
+		require.Len(t, list, 1)
+		assert.Equal(t, "King", list[0].Author.Name)
+	}
+
+	require.NoError(t, u.Delete(asAuthor(1), 4))
+	_, _, err := u.FetchPage(context.TODO(), filter, domain.OffsetRequest{Page: 2})
+	require.NoError(t, err, "deleting forgets the count")
+	mockArticleRepo.AssertExpectations(t)
+
+	for _, page := range []domain.OffsetRequest{{Page: -1}, {Page: math.MaxInt64, PerPage: 10}, {PerPage: 101}} {
+		_, _, err = u.FetchPage(context.TODO(), filter, page)
+		assert.True(t, errors.Is(err, domain.ErrBadInput), "%+v", page)
+	}
+}
+
+func TestArticleUseCase_FetchPageWithoutCountCache(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockArticleRepo.On("Count", mock.Anything, domain.ArticleFilter{}).Return(int64(0), nil).Twice()
+	mockArticleRepo.On("FetchOffset", mock.Anything, domain.ArticleFilter{}, mock.AnythingOfType("domain.OffsetRequest")).Return([]domain.Article{}, nil)
+	u := NewArticleUseCaseWithPaging(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2, domain.DefaultPageSize, 0)
+
+	for i := 0; i < 2; i++ {
+		_, _, err := u.FetchPage(context.TODO(), domain.ArticleFilter{}, domain.OffsetRequest{})
+		require.NoError(t, err)
+	}
+
+	mockArticleRepo.AssertExpectations(t)
+}
+
+func TestArticleUseCase_FetchByAuthor(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockAuthor := domain.Author{
+		ID:   1,
+		Name: "King",
+	}
+	mockListArticle := []domain.Article{
+		{ID: 1, Title: "It", Author: domain.Author{ID: 1}},
+		{ID: 2, Title: "Carrie", Author: domain.Author{ID: 1}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockArticleRepo.On("FetchByAuthor", mock.Anything, int64(1), domain.PageRequest{Num: 10, Sort: domain.Sort{Field: domain.SortCreatedAt}}).
+			Return(mockListArticle, domain.PageInfo{HasNext: true}, nil).Once()
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(1)).Return(mockAuthor, nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		list, info, err := u.FetchByAuthor(context.TODO(), 1, domain.PageRequest{})
+		assert.NoError(t, err)
+		assert.True(t, info.HasNext)
+		for _, article := range list {
+			assert.Equal(t, mockAuthor, article.Author)
+		}
+
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown-author", func(t *testing.T) {
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.Author{}, domain.ErrNotFound).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		list, _, err := u.FetchByAuthor(context.TODO(), 2, domain.PageRequest{Num: 5})
+		assert.Equal(t, domain.ErrNotFound, err)
+		assert.Len(t, list, 0)
+
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+}
+
+func TestArticleUseCase_Search(t *testing.T) {
+	mockArticleRepo := new(mocks.ArticleRepository)
+	mockResults := []domain.ArticleSearchResult{
+		{Article: domain.Article{ID: 1, Title: "It", Author: domain.Author{ID: 1}}, Score: 2, Snippet: "<mark>It</mark>"},
+	}
+	mockAuthor := domain.Author{
+		ID:   1,
+		Name: "King",
+	}
+
+	after := &domain.SearchPosition{Score: 3, ID: 7}
+	mockArticleRepo.On("Search", mock.Anything, "it", after, int64(10)).Return(mockResults, domain.PageInfo{HasPrev: true}, nil).Once()
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{1}).Return(map[int64]domain.Author{1: mockAuthor}, nil)
+	u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+	results, info, err := u.Search(context.TODO(), "it", after, 0)
+	assert.NoError(t, err)
+	assert.False(t, info.HasNext)
+	assert.Len(t, results, 1)
+	assert.Equal(t, mockAuthor, results[0].Article.Author)
+	assert.Equal(t, "<mark>It</mark>", results[0].Snippet)
+
+	mockArticleRepo.AssertExpectations(t)
+	mockAuthorRepo.AssertExpectations(t)
+}
+
+func TestArticleUseCase_FillAuthorDetails(t *testing.T) {
+	articles := func() []domain.Article {
+		return []domain.Article{
+			{ID: 1, Author: domain.Author{ID: 1}},
+			{ID: 2, Author: domain.Author{ID: 2}},
+			{ID: 3, Author: domain.Author{ID: 1}},
+			{ID: 4, Author: domain.Author{ID: 3}},
+		}
+	}
+	king := domain.Author{ID: 1, Name: "King"}
+	austen := domain.Author{ID: 3, Name: "Austen"}
+
+	t.Run("one-query-and-missing-authors", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, mock.AnythingOfType("domain.PageRequest")).
+			Return(articles(), domain.PageInfo{HasNext: true}, nil).Once()
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{1, 2, 3}).
+			Return(map[int64]domain.Author{1: king, 3: austen}, nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		list, info, err := u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{})
+		require.NoError(t, err, "a missing author does not fail the page")
+		assert.True(t, info.HasNext)
+		assert.Equal(t, []domain.Author{king, {ID: 2}, king, austen},
+			[]domain.Author{list[0].Author, list[1].Author, list[2].Author, list[3].Author})
+		assert.True(t, list[1].AuthorMissing)
+		assert.False(t, list[0].AuthorMissing)
+
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{1, 2}).Return(map[int64]domain.Author{1: king}, nil).Once()
+		mockAuthorRepo.On("GetByIDs", mock.Anything, []int64{3}).Return(map[int64]domain.Author{3: austen}, nil).Once()
+
+		loader := newAuthorLoader(mockAuthorRepo, 2)
+		for _, a := range articles() {
+			loader.add(a.Author.ID)
+		}
+
+		authors, missing, err := loader.load(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]domain.Author{1: king, 3: austen}, authors)
+		assert.Equal(t, []int64{2}, missing)
+
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, mock.AnythingOfType("domain.PageRequest")).
+			Return(articles(), domain.PageInfo{HasNext: true}, nil).Once()
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		_, info, err := u.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{})
+		assert.EqualError(t, err, "connection refused")
+		assert.False(t, info.HasNext)
+	})
+}
+
 func TestArticleUseCase_GetByID(t *testing.T) {
 	mockArticleRepo := new(mocks.ArticleRepository)
 	mockArticle := domain.Article{
@@ -89,6 +320,22 @@ func TestArticleUseCase_GetByID(t *testing.T) {
 		mockAuthorRepo.AssertExpectations(t)
 	})
 
+	t.Run("missing-author", func(t *testing.T) {
+		mockArticleRepo.On("GetByID", mock.Anything, int64(5)).Return(domain.Article{ID: 5, Author: domain.Author{ID: 2}}, nil).Once()
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.Author{}, domain.ErrNotFound).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		a, err := u.GetByID(context.TODO(), 5)
+
+		require.NoError(t, err, "a deleted author does not hide the article")
+		assert.True(t, a.AuthorMissing)
+		assert.Equal(t, domain.Author{ID: 2}, a.Author)
+
+		mockArticleRepo.AssertExpectations(t)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		mockArticleRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(domain.Article{}, errors.New("unexpected err")).Once()
 
@@ -121,7 +368,7 @@ func TestArticleUseCase_Store(t *testing.T) {
 		mockAuthorRepo := new(mocks.AuthorRepository)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Store(context.TODO(), &tempMockArticle)
+		err := u.Store(asAuthor(1), &tempMockArticle)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockArticle.Title, tempMockArticle.Title)
@@ -130,21 +377,33 @@ func TestArticleUseCase_Store(t *testing.T) {
 	t.Run("existing-title", func(t *testing.T) {
 		existingArticle := mockArticle
 		mockArticleRepo.On("GetByTitle", mock.Anything, mock.AnythingOfType("string")).Return(existingArticle, nil).Once()
-		mockAuthor := domain.Author{
-			ID:   1,
-			Name: "Martin",
-		}
 		mockAuthorRepo := new(mocks.AuthorRepository)
-		mockAuthorRepo.On("GetByID", mock.Anything, mock.AnythingOfType("int64")).Return(mockAuthor, nil)
 
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Store(context.TODO(), &mockArticle)
+		err := u.Store(asAuthor(1), &mockArticle)
 
-		assert.Error(t, err)
+		assert.Equal(t, domain.ErrConflict, err)
 		mockArticleRepo.AssertExpectations(t)
 		mockAuthorRepo.AssertExpectations(t)
 	})
+	t.Run("existing-title-write-only-key", func(t *testing.T) {
+		mockArticleRepo.On("GetByTitle", mock.Anything, mockArticle.Title).Return(mockArticle, nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+		ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "api-key:1", AuthorID: 1, Scopes: []string{domain.ScopeWriteArticles}})
+		article := mockArticle
+		assert.Equal(t, domain.ErrConflict, u.Store(ctx, &article))
+		mockArticleRepo.AssertExpectations(t)
+	})
+	t.Run("title-lookup-fails", func(t *testing.T) {
+		mockArticleRepo.On("GetByTitle", mock.Anything, mockArticle.Title).Return(domain.Article{}, errors.New("connection refused")).Once()
+		u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+		article := mockArticle
+		assert.EqualError(t, u.Store(asAuthor(1), &article), "connection refused")
+		mockArticleRepo.AssertExpectations(t)
+	})
 
 }
 
@@ -163,7 +422,7 @@ func TestArticleUseCase_Delete(t *testing.T) {
 		mockAuthorRepo := new(mocks.AuthorRepository)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Delete(context.TODO(), mockArticle.ID)
+		err := u.Delete(asAdmin(), mockArticle.ID)
 
 		assert.NoError(t, err)
 		mockArticleRepo.AssertExpectations(t)
@@ -175,7 +434,7 @@ func TestArticleUseCase_Delete(t *testing.T) {
 		mockAuthorRepo := new(mocks.AuthorRepository)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Delete(context.TODO(), mockArticle.ID)
+		err := u.Delete(asAdmin(), mockArticle.ID)
 
 		assert.Error(t, err)
 		mockArticleRepo.AssertExpectations(t)
@@ -187,7 +446,7 @@ func TestArticleUseCase_Delete(t *testing.T) {
 		mockAuthorRepo := new(mocks.AuthorRepository)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Delete(context.TODO(), mockArticle.ID)
+		err := u.Delete(asAdmin(), mockArticle.ID)
 
 		assert.Error(t, err)
 		mockArticleRepo.AssertExpectations(t)
@@ -197,20 +456,139 @@ func TestArticleUseCase_Delete(t *testing.T) {
 
 func TestArticleUseCase_Update(t *testing.T) {
 	mockArticleRepo := new(mocks.ArticleRepository)
-	mockArticle := domain.Article{
-		Title:   "Hello",
-		Content: "Content",
-		ID:      23,
+	version := time.Now().Add(-time.Hour).Truncate(time.Second)
+	storedArticle := domain.Article{
+		ID:        23,
+		Title:     "Hello",
+		Content:   "Content",
+		Author:    domain.Author{ID: 1},
+		UpdatedAt: version,
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockArticleRepo.On("Update", mock.Anything, &mockArticle).Once().Return(nil)
+		mockArticle := domain.Article{
+			Title:   "Hello",
+			Content: "New content",
+			ID:      23,
+		}
+		mockArticleRepo.On("GetByID", mock.Anything, mockArticle.ID).Return(storedArticle, nil).Once()
+		mockArticleRepo.On("Update", mock.Anything, &mockArticle, version).Once().Return(nil)
 
 		mockAuthorRepo := new(mocks.AuthorRepository)
 		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
 
-		err := u.Update(context.TODO(), &mockArticle)
+		err := u.Update(asAuthor(1), &mockArticle, version)
 		assert.NoError(t, err)
+		assert.True(t, mockArticle.UpdatedAt.After(version))
+		assert.Equal(t, storedArticle.Author, mockArticle.Author)
 		mockArticleRepo.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+	t.Run("stale-version", func(t *testing.T) {
+		mockArticle := domain.Article{
+			Title: "Hello",
+			ID:    23,
+		}
+		mockArticleRepo.On("GetByID", mock.Anything, mockArticle.ID).Return(storedArticle, nil).Once()
+
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAuthor(1), &mockArticle, version.Add(-time.Second))
+		assert.Equal(t, domain.ErrPreconditionFailed, err)
+		mockArticleRepo.AssertExpectations(t)
+	})
+	t.Run("title-taken", func(t *testing.T) {
+		mockArticle := domain.Article{
+			Title: "Taken",
+			ID:    23,
+		}
+		mockArticleRepo.On("GetByID", mock.Anything, mockArticle.ID).Return(storedArticle, nil).Once()
+		mockArticleRepo.On("GetByTitle", mock.Anything, "Taken").Return(domain.Article{ID: 7, Title: "Taken"}, nil).Once()
+
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAuthor(1), &mockArticle, time.Time{})
+		assert.Equal(t, domain.ErrConflict, err)
+		mockArticleRepo.AssertExpectations(t)
+	})
+}
+
+func TestArticleUseCase_Authorization(t *testing.T) {
+	stored := domain.Article{ID: 23, Title: "Hello", Author: domain.Author{ID: 1}}
+
+	t.Run("anonymous", func(t *testing.T) {
+		u := NewArticleUseCase(new(mocks.ArticleRepository), new(mocks.AuthorRepository), time.Second*2)
+
+		assert.Equal(t, domain.ErrUnauthorized, u.Store(context.TODO(), &domain.Article{Title: "Hi"}))
+		assert.Equal(t, domain.ErrUnauthorized, u.Update(context.TODO(), &domain.Article{ID: 23}, time.Time{}))
+		assert.Equal(t, domain.ErrUnauthorized, u.Delete(context.TODO(), 23))
+	})
+	t.Run("other-author", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("GetByID", mock.Anything, int64(23)).Return(stored, nil).Twice()
+		u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+		assert.Equal(t, domain.ErrForbidden, u.Update(asAuthor(2), &domain.Article{ID: 23, Title: "Hello"}, time.Time{}))
+		assert.Equal(t, domain.ErrForbidden, u.Delete(asAuthor(2), 23))
+		mockArticleRepo.AssertExpectations(t)
+	})
+	t.Run("store-sets-author-from-token", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("GetByTitle", mock.Anything, "Hi").Return(domain.Article{}, domain.ErrNotFound).Once()
+		mockArticleRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Article")).Return(nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+		article := domain.Article{Title: "Hi", Author: domain.Author{ID: 9}}
+		require.NoError(t, u.Store(asAuthor(3), &article))
+		assert.Equal(t, int64(3), article.Author.ID)
+		mockArticleRepo.AssertExpectations(t)
+	})
+	t.Run("read-only-key", func(t *testing.T) {
+		u := NewArticleUseCase(new(mocks.ArticleRepository), new(mocks.AuthorRepository), time.Second*2)
+
+		ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "api-key:1", AuthorID: 1, Scopes: []string{domain.ScopeReadArticles}})
+		assert.Equal(t, domain.ErrForbidden, u.Store(ctx, &domain.Article{Title: "Hi"}))
+		assert.Equal(t, domain.ErrForbidden, u.Delete(ctx, 23))
+	})
+	t.Run("write-only-key", func(t *testing.T) {
+		u := NewArticleUseCase(new(mocks.ArticleRepository), new(mocks.AuthorRepository), time.Second*2)
+
+		ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "api-key:1", AuthorID: 1, Scopes: []string{domain.ScopeWriteArticles}})
+		_, err := u.GetByID(ctx, 23)
+		assert.Equal(t, domain.ErrForbidden, err)
+		_, _, err = u.Fetch(ctx, domain.ArticleFilter{}, domain.PageRequest{})
+		assert.Equal(t, domain.ErrForbidden, err)
+		_, _, err = u.Search(ctx, "hello", nil, 0)
+		assert.Equal(t, domain.ErrForbidden, err)
+	})
+	t.Run("read-key-reads", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("GetByID", mock.Anything, int64(23)).Return(stored, nil).Once()
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByID", mock.Anything, stored.Author.ID).Return(stored.Author, nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, mockAuthorRepo, time.Second*2)
+
+		ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "api-key:1", Scopes: []string{domain.ScopeReadArticles}})
+		_, err := u.GetByID(ctx, 23)
+		assert.NoError(t, err)
+		mockArticleRepo.AssertExpectations(t)
+	})
+	t.Run("store-needs-an-author", func(t *testing.T) {
+		u := NewArticleUseCase(new(mocks.ArticleRepository), new(mocks.AuthorRepository), time.Second*2)
+
+		ctx := domain.WithIdentity(context.TODO(), domain.Identity{Subject: "reader"})
+		assert.Equal(t, domain.ErrForbidden, u.Store(ctx, &domain.Article{Title: "Hi"}))
+	})
+	t.Run("admin-reassigns-author", func(t *testing.T) {
+		mockArticleRepo := new(mocks.ArticleRepository)
+		mockArticleRepo.On("GetByID", mock.Anything, int64(23)).Return(stored, nil).Once()
+		mockArticleRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Article"), stored.UpdatedAt).Return(nil).Once()
+		u := NewArticleUseCase(mockArticleRepo, new(mocks.AuthorRepository), time.Second*2)
+
+		article := domain.Article{ID: 23, Title: "Hello", Author: domain.Author{ID: 5}}
+		require.NoError(t, u.Update(asAdmin(), &article, time.Time{}))
+		assert.Equal(t, int64(5), article.Author.ID)
+		mockArticleRepo.AssertExpectations(t)
+	})
+}
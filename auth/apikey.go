@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/labstack/echo"
+)
+
+// HeaderAPIKey carries the API key of a machine client.
+const HeaderAPIKey = "X-API-Key"
+
+var errTwoCredentials = domain.NewError(domain.CodeBadInput, "send either a bearer token or an API key, not both")
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header
+// through keys and stores the caller with domain.WithIdentity. It runs after
+// the bearer token middleware, if any; requests without the header pass
+// through untouched.
+func APIKeyMiddleware(keys domain.APIKeyUseCase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			secret := req.Header.Get(HeaderAPIKey)
+			if secret == "" {
+				return next(c)
+			}
+
+			if _, ok := domain.IdentityFromContext(req.Context()); ok {
+				return errTwoCredentials
+			}
+
+			id, err := keys.Authenticate(req.Context(), secret)
+			if err != nil {
+				logging.FromContext(req.Context()).Info("rejected API key", "err", err)
+				return err
+			}
+
+			ctx := domain.WithIdentity(req.Context(), id)
+			ctx = logging.WithContext(ctx, logging.FromContext(ctx).With("subject", id.Subject))
+			c.SetRequest(req.WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
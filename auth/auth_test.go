@@ -0,0 +1,248 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/auth"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims auth.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func claims(sub string) auth.Claims {
+	return auth.Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   sub,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+}
+
+func TestAuthenticateHS256(t *testing.T) {
+	a, err := auth.New(config.Auth{HMACSecret: secret, Issuer: "https://issuer.example", Audience: "articles"})
+	require.NoError(t, err)
+
+	valid := claims("42")
+	valid.Roles = []string{domain.RoleAdmin}
+	valid.Issuer = "https://issuer.example"
+	valid.Audience = jwt.ClaimStrings{"articles"}
+
+	id, err := a.Authenticate(sign(t, jwt.SigningMethodHS256, []byte(secret), "", valid))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Identity{Subject: "42", Roles: []string{domain.RoleAdmin}}, id, "a numeric sub is not an author id")
+	assert.True(t, id.IsAdmin())
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "https://evil.example"
+
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.ClaimStrings{"billing"}
+
+	noSubject := valid
+	noSubject.Subject = ""
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	tests := map[string]string{
+		"expired":        sign(t, jwt.SigningMethodHS256, []byte(secret), "", expired),
+		"wrong issuer":   sign(t, jwt.SigningMethodHS256, []byte(secret), "", wrongIssuer),
+		"wrong audience": sign(t, jwt.SigningMethodHS256, []byte(secret), "", wrongAudience),
+		"no subject":     sign(t, jwt.SigningMethodHS256, []byte(secret), "", noSubject),
+		"no expiry":      sign(t, jwt.SigningMethodHS256, []byte(secret), "", noExpiry),
+		"wrong secret":   sign(t, jwt.SigningMethodHS256, []byte("another secret of thirty-two bytes"), "", valid),
+		"garbage":        "not.a.token",
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(token)
+			assert.True(t, errors.Is(err, domain.ErrUnauthorized), "got %v", err)
+		})
+	}
+}
+
+func TestAuthenticateRS256(t *testing.T) {
+	dir := t.TempDir()
+
+	pemKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&pemKey.PublicKey)
+	require.NoError(t, err)
+	pemFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	jwksKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ignored"},
+		{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(jwksKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(jwksKey.E)).Bytes()),
+		},
+	}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	a, err := auth.New(config.Auth{RSAPublicKeyFile: pemFile, JWKSFile: jwksFile})
+	require.NoError(t, err)
+
+	c := claims("alice")
+	c.AuthorID = 7
+
+	id, err := a.Authenticate(sign(t, jwt.SigningMethodRS256, jwksKey, "k1", c))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id.AuthorID)
+
+	_, err = a.Authenticate(sign(t, jwt.SigningMethodRS256, pemKey, "", c))
+	assert.NoError(t, err, "the PEM key verifies tokens without a kid")
+
+	_, err = a.Authenticate(sign(t, jwt.SigningMethodRS256, pemKey, "k1", c))
+	assert.Error(t, err, "the kid picks the key")
+
+	_, err = a.Authenticate(sign(t, jwt.SigningMethodHS256, der, "", c))
+	assert.Error(t, err, "HS256 is refused when only RSA keys are configured")
+}
+
+func TestNewRequiresAKey(t *testing.T) {
+	_, err := auth.New(config.Auth{})
+	assert.Error(t, err)
+
+	_, err = auth.New(config.Auth{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	a, err := auth.New(config.Auth{HMACSecret: secret})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(a.Middleware)
+	e.GET("/whoami", func(c echo.Context) error {
+		id, ok := domain.IdentityFromContext(c.Request().Context())
+		if !ok {
+			return c.String(http.StatusOK, "anonymous")
+		}
+		return c.String(http.StatusOK, id.Subject)
+	})
+
+	tests := map[string]struct {
+		header string
+		status int
+		body   string
+	}{
+		"anonymous":  {"", http.StatusOK, "anonymous"},
+		"valid":      {"Bearer " + sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims("bob")), http.StatusOK, "bob"},
+		"lower case": {"bearer " + sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims("bob")), http.StatusOK, "bob"},
+		"invalid":    {"Bearer nope", http.StatusUnauthorized, ""},
+		"basic":      {"Basic Ym9iOnNlY3JldA==", http.StatusUnauthorized, ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tc.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, tc.body, rec.Body.String())
+			} else {
+				assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	keys := new(mocks.APIKeyUseCase)
+	keys.On("Authenticate", mock.Anything, "good").Return(domain.Identity{Subject: "api-key:1", Scopes: []string{domain.ScopeReadArticles}}, nil)
+	keys.On("Authenticate", mock.Anything, "bad").Return(domain.Identity{}, domain.ErrUnauthorized)
+
+	a, err := auth.New(config.Auth{HMACSecret: secret})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(a.Middleware)
+	e.Use(auth.APIKeyMiddleware(keys))
+	e.GET("/whoami", func(c echo.Context) error {
+		id, _ := domain.IdentityFromContext(c.Request().Context())
+		return c.String(http.StatusOK, id.Subject)
+	})
+
+	tests := map[string]struct {
+		apiKey string
+		bearer bool
+		status int
+	}{
+		"none":    {"", false, http.StatusOK},
+		"valid":   {"good", false, http.StatusOK},
+		"invalid": {"bad", false, http.StatusUnauthorized},
+		"both":    {"good", true, http.StatusBadRequest},
+		"bearer":  {"", true, http.StatusOK},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tc.apiKey != "" {
+				req.Header.Set(auth.HeaderAPIKey, tc.apiKey)
+			}
+			if tc.bearer {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims("bob")))
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if name == "valid" {
+				assert.Equal(t, "api-key:1", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/labstack/echo"
+	"strings"
+)
+
+var errNotBearer = domain.NewError(domain.CodeUnauthorized, "the Authorization header must hold a bearer token")
+
+// Middleware authenticates requests carrying an Authorization header and
+// stores the caller with domain.WithIdentity. Requests without the header go
+// through anonymously; a header that does not verify is rejected with 401.
+func (a *Authenticator) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		header := req.Header.Get(echo.HeaderAuthorization)
+		if header == "" {
+			return next(c)
+		}
+
+		const prefix = "bearer "
+		if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_request"`)
+			return errNotBearer
+		}
+
+		id, err := a.Authenticate(strings.TrimSpace(header[len(prefix):]))
+		if err != nil {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			logging.FromContext(req.Context()).Info("rejected bearer token", "err", err)
+			return err
+		}
+
+		ctx := domain.WithIdentity(req.Context(), id)
+		ctx = logging.WithContext(ctx, logging.FromContext(ctx).With("subject", id.Subject))
+		c.SetRequest(req.WithContext(ctx))
+
+		return next(c)
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"github.com/angelRaynov/clean-architecture/cursor"
+	deliveryHttp "github.com/angelRaynov/clean-architecture/delivery/http"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"net/http"
+)
+
+// cursorList names the cursors of the author list.
+const cursorList = "authors"
+
+type AuthorHandler struct {
+	AuthorUseCase domain.AuthorUseCase
+	PageSize      domain.PageSize
+	Cursors       *cursor.Codec
+}
+
+func NewAuthorHandler(e *echo.Echo, useCase domain.AuthorUseCase) {
+	NewAuthorHandlerWithPaging(e, useCase, domain.DefaultPageSize, cursor.NewCodec(nil))
+}
+
+// NewAuthorHandlerWithPaging registers the author routes. pageSize must
+// match the one of the use case, and every replica must sign cursors with the
+// same key.
+func NewAuthorHandlerWithPaging(e *echo.Echo, useCase domain.AuthorUseCase, pageSize domain.PageSize, cursors *cursor.Codec) {
+	handler := &AuthorHandler{
+		AuthorUseCase: useCase,
+		PageSize:      pageSize,
+		Cursors:       cursors.For(cursorList),
+	}
+
+	e.GET("/authors", handler.FetchAuthor)
+	e.GET("/authors/:id", handler.GetByID)
+	e.POST("/authors", handler.Store)
+	e.PUT("/authors/:id", handler.Update)
+	e.DELETE("/authors/:id", handler.Delete)
+}
+
+// FetchAuthor lists authors oldest first, a page at a time, in the Page
+// envelope of every list.
+func (ah *AuthorHandler) FetchAuthor(ec echo.Context) error {
+	page, err := deliveryHttp.PageRequest(ec, ah.PageSize, ah.Cursors)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	listAuthor, info, err := ah.AuthorUseCase.Fetch(ctx, page)
+	if err != nil {
+		return err
+	}
+
+	return deliveryHttp.WritePage(ec, deliveryHttp.NewPage(listAuthor, info, page, ah.Cursors, domain.Author.Position))
+}
+
+func (ah *AuthorHandler) GetByID(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+
+	author, err := ah.AuthorUseCase.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, author)
+}
+
+func (ah *AuthorHandler) Store(ec echo.Context) error {
+	var author domain.Author
+	err := ec.Bind(&author)
+	if err != nil {
+		return err
+	}
+
+	if err = isValidRequest(&author); err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	err = ah.AuthorUseCase.Store(ctx, &author)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusCreated, author)
+}
+
+func (ah *AuthorHandler) Update(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
+	}
+
+	var author domain.Author
+	err = ec.Bind(&author)
+	if err != nil {
+		return err
+	}
+
+	author.ID = id
+
+	if err = isValidRequest(&author); err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+	err = ah.AuthorUseCase.Update(ctx, &author)
+	if err != nil {
+		return err
+	}
+
+	return ec.JSON(http.StatusOK, author)
+}
+
+func (ah *AuthorHandler) Delete(ec echo.Context) error {
+	id, err := deliveryHttp.ParseID(ec)
+	if err != nil {
+		return err
+	}
+
+	ctx := ec.Request().Context()
+
+	err = ah.AuthorUseCase.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ec.NoContent(http.StatusNoContent)
+}
+
+func isValidRequest(a *domain.Author) error {
+	validate := problem.NewValidator()
+	return validate.Struct(a)
+}
@@ -0,0 +1,214 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/auth"
+	authorHttp "github.com/angelRaynov/clean-architecture/author/delivery/http"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	"github.com/angelRaynov/clean-architecture/author/usecase"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/cursor"
+	deliveryHttp "github.com/angelRaynov/clean-architecture/delivery/http"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const hmacSecret = "0123456789abcdef0123456789abcdef"
+
+type server struct {
+	t    *testing.T
+	echo *echo.Echo
+	// token is sent as the bearer token of every request unless empty.
+	token string
+}
+
+func newServer(t *testing.T, pageSize domain.PageSize) (*server, domain.AuthorRepository) {
+	authorRepo := authMemory.NewAuthorRepository()
+
+	authenticator, err := auth.New(config.Auth{HMACSecret: hmacSecret})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(authenticator.Middleware)
+	authorHttp.NewAuthorHandlerWithPaging(e, usecase.NewAuthorUseCaseWithPageSize(authorRepo, time.Second*2, pageSize),
+		pageSize, cursor.NewCodec([]byte(hmacSecret)))
+
+	return &server{t: t, echo: e, token: signToken(t, auth.Claims{Roles: []string{domain.RoleAdmin}})}, authorRepo
+}
+
+func signToken(t *testing.T, claims auth.Claims) string {
+	claims.Subject = "test"
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(hmacSecret))
+	require.NoError(t, err)
+
+	return token
+}
+
+func (s *server) do(method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if s.token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+s.token)
+	}
+
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+
+	assert.Equal(t, problem.ContentType, rec.Header().Get(echo.HeaderContentType))
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, rec.Code, p.Status)
+
+	return p
+}
+
+func TestAuthorHandler_EndToEnd(t *testing.T) {
+	s, _ := newServer(t, domain.DefaultPageSize)
+
+	rec := s.do(http.MethodPost, "/authors", `{"name":"King"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created domain.Author
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, int64(1), created.ID)
+
+	rec = s.do(http.MethodGet, "/authors/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	s.token = signToken(t, auth.Claims{AuthorID: 1})
+	rec = s.do(http.MethodPut, "/authors/1", `{"name":"Richard Bachman"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var updated domain.Author
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.Equal(t, "Richard Bachman", updated.Name)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+	rec = s.do(http.MethodDelete, "/authors/1", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "only admins delete authors")
+
+	s.token = ""
+	rec = s.do(http.MethodPost, "/authors", `{"name":"Austen"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	s.token = signToken(t, auth.Claims{Roles: []string{domain.RoleAdmin}})
+	rec = s.do(http.MethodDelete, "/authors/1", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestAuthorHandler_Problems(t *testing.T) {
+	s, _ := newServer(t, domain.DefaultPageSize)
+
+	for _, req := range []struct{ method, target string }{
+		{http.MethodGet, "/authors/abc"},
+		{http.MethodPut, "/authors/abc"},
+		{http.MethodDelete, "/authors/abc"},
+	} {
+		rec := s.do(req.method, req.target, `{"name":"King"}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code, req.target)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, req.target)
+	}
+
+	for _, req := range []struct{ method, target, body string }{
+		{http.MethodGet, "/authors/7", ""},
+		{http.MethodPut, "/authors/7", `{"name":"Nobody"}`},
+		{http.MethodDelete, "/authors/7", ""},
+	} {
+		rec := s.do(req.method, req.target, req.body)
+		require.Equal(t, http.StatusNotFound, rec.Code, req.target)
+		assert.Equal(t, domain.CodeNotFound, decodeProblem(t, rec).Code, req.target)
+	}
+
+	rec := s.do(http.MethodPost, "/authors", `{}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	p := decodeProblem(t, rec)
+	assert.Equal(t, domain.CodeValidation, p.Code)
+	require.Len(t, p.Errors, 1)
+	assert.Equal(t, "name", p.Errors[0].Field)
+
+	rec = s.do(http.MethodPut, "/authors/1", `{"name":""}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, domain.CodeValidation, decodeProblem(t, rec).Code)
+}
+
+func TestAuthorHandler_Paging(t *testing.T) {
+	s, authorRepo := newServer(t, domain.PageSize{Default: 1, Max: 3})
+
+	// King and Austen share a timestamp; paging must not skip either.
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"King", "Austen", "Tolkien"} {
+		au := domain.Author{Name: name, CreatedAt: created.Add(time.Duration(i/2) * time.Hour)}
+		require.NoError(t, authorRepo.Store(context.TODO(), &au))
+	}
+
+	get := func(target string) ([]string, deliveryHttp.Page) {
+		rec := s.do(http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var list []domain.Author
+		page := deliveryHttp.Page{Data: &list}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, rec.Header().Get("X-Cursor"), page.NextCursor)
+
+		names := make([]string, 0, len(list))
+		for _, au := range list {
+			names = append(names, au.Name)
+		}
+		return names, page
+	}
+
+	names, first := get("/authors")
+	assert.Equal(t, []string{"King"}, names)
+	assert.True(t, first.HasMore)
+	assert.Empty(t, first.PrevCursor)
+	assert.Equal(t, int64(1), first.PageSize)
+
+	names, second := get("/authors?num=2&after=" + first.NextCursor)
+	assert.Equal(t, []string{"Austen", "Tolkien"}, names)
+	assert.False(t, second.HasMore)
+	assert.Empty(t, second.NextCursor)
+	assert.NotEmpty(t, second.PrevCursor)
+
+	names, back := get("/authors?before=" + second.PrevCursor)
+	assert.Equal(t, []string{"King"}, names)
+	assert.Empty(t, back.PrevCursor, "the first page has nothing before it")
+
+	names, _ = get("/authors?cursor=" + first.NextCursor)
+	assert.Equal(t, []string{"Austen"}, names, "cursor is an alias of after")
+
+	tampered := []byte(first.NextCursor)
+	tampered[3] ^= 1
+
+	for _, target := range []string{
+		"/authors?num=ten",
+		"/authors?num=-1",
+		"/authors?num=4",
+		"/authors?after=" + string(tampered),
+		"/authors?after=" + cursor.NewCodec(nil).Encode(domain.Sort{}, domain.Position{ID: 1}),
+		"/authors?after=" + cursor.NewCodec([]byte(hmacSecret)).For("articles").Encode(domain.Sort{}, domain.Position{ID: 1}),
+		"/authors?after=" + first.NextCursor + "&before=" + second.PrevCursor,
+	} {
+		rec := s.do(http.MethodGet, target, "")
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Equal(t, domain.CodeBadInput, decodeProblem(t, rec).Code, target)
+	}
+}
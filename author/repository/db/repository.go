@@ -3,26 +3,40 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/article/repository"
+	"github.com/angelRaynov/clean-architecture/dialect"
 	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"strings"
 )
 
 type authorRepo struct {
-	DB *sql.DB
+	DB      *sql.DB
+	dialect dialect.Dialect
 }
 
 func NewAuthorRepository(db *sql.DB) domain.AuthorRepository {
+	return NewAuthorRepositoryWithDialect(db, dialect.MySQL)
+}
+
+// NewAuthorRepositoryWithDialect returns a repository for a PostgreSQL or
+// SQLite database, or for MySQL when d is dialect.MySQL.
+func NewAuthorRepositoryWithDialect(db *sql.DB, d dialect.Dialect) domain.AuthorRepository {
 	return &authorRepo{
-		DB: db,
+		DB:      db,
+		dialect: d,
 	}
 }
 
 func (a *authorRepo) getOne(ctx context.Context, query string, args ...interface{}) (domain.Author, error) {
-	stmt, err := a.DB.PrepareContext(ctx, query)
+	stmt, err := a.DB.PrepareContext(ctx, a.dialect.Rebind(query))
 	if err != nil {
 		return domain.Author{}, err
 	}
+	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, args...)
+	row := stmt.QueryRowContext(ctx, a.dialect.Args(args...)...)
 
 	var res domain.Author
 
@@ -33,9 +47,200 @@ func (a *authorRepo) getOne(ctx context.Context, query string, args ...interface
 		&res.UpdatedAt,
 	)
 
+	if err == sql.ErrNoRows {
+		return domain.Author{}, domain.ErrNotFound
+	}
+
 	return res, err
 }
+
+func (a *authorRepo) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.Author, error) {
+	rows, err := a.DB.QueryContext(ctx, a.dialect.Rebind(query), a.dialect.Args(args...)...)
+	if err != nil {
+		logging.FromContext(ctx).Error("query failed", "repository", "author", "err", err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logging.FromContext(ctx).Error("closing rows failed", "repository", "author", "err", errRow)
+		}
+	}()
+
+	result := make([]domain.Author, 0)
+	for rows.Next() {
+		var t domain.Author
+		err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+
+		if err != nil {
+			logging.FromContext(ctx).Error("scanning row failed", "repository", "author", "err", err)
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		logging.FromContext(ctx).Error("reading rows failed", "repository", "author", "err", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Fetch runs a keyset query on (created_at, id), so authors created in the
+// same second are neither skipped nor repeated.
+func (a *authorRepo) Fetch(ctx context.Context, page domain.PageRequest) ([]domain.Author, domain.PageInfo, error) {
+	query := `SELECT id, name, created_at, updated_at FROM author`
+	var args []interface{}
+
+	// Pages before a cursor are read backwards.
+	op, dir, cursor := ">", "ASC", page.After
+	if page.Before != nil {
+		op, dir, cursor = "<", "DESC", page.Before
+	}
+
+	if cursor != nil {
+		query += ` WHERE (created_at ` + op + ` ? OR (created_at = ? AND id ` + op + ` ?))`
+		args = append(args, cursor.Time, cursor.Time, cursor.ID)
+	}
+
+	query += ` ORDER BY created_at ` + dir + `, id ` + dir + ` LIMIT ?`
+	args = append(args, page.Num+1)
+
+	res, err := a.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	res, info := repository.Window(res, page)
+	return res, info, nil
+}
+
 func (a *authorRepo) GetByID(ctx context.Context, id int64) (domain.Author, error) {
 	query := `SELECT id,name,created_at,updated_at FROM author WHERE id=?`
 	return a.getOne(ctx, query, id)
 }
+
+func (a *authorRepo) GetByIDs(ctx context.Context, ids []int64) (map[int64]domain.Author, error) {
+	res := make(map[int64]domain.Author, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := `SELECT id, name, created_at, updated_at FROM author WHERE id IN (` +
+		strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + `)`
+
+	list, err := a.fetch(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, au := range list {
+		res[au.ID] = au
+	}
+
+	return res, nil
+}
+
+func (a *authorRepo) Update(ctx context.Context, au *domain.Author) error {
+	query := `UPDATE author SET name=?, updated_at=? WHERE id = ?`
+
+	stmt, err := a.DB.PrepareContext(ctx, a.dialect.Rebind(query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, a.dialect.Args(au.Name, au.UpdatedAt, au.ID)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	if rowsAffected != 1 {
+		err = fmt.Errorf("err: rows affected %d", rowsAffected)
+	}
+
+	return err
+}
+
+func (a *authorRepo) Store(ctx context.Context, au *domain.Author) error {
+	query := `INSERT INTO author (name, created_at, updated_at) VALUES (?, ?, ?)`
+	if a.dialect.ReturningID() {
+		query += ` RETURNING id`
+	}
+
+	stmt, err := a.DB.PrepareContext(ctx, a.dialect.Rebind(query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if a.dialect.ReturningID() {
+		return stmt.QueryRowContext(ctx, a.dialect.Args(au.Name, au.CreatedAt, au.UpdatedAt)...).Scan(&au.ID)
+	}
+
+	res, err := stmt.ExecContext(ctx, a.dialect.Args(au.Name, au.CreatedAt, au.UpdatedAt)...)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	au.ID = lastID
+
+	return err
+}
+
+func (a *authorRepo) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM author WHERE id = ?`
+
+	stmt, err := a.DB.PrepareContext(ctx, a.dialect.Rebind(query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, a.dialect.Args(id)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+
+	if rowsAffected != 1 {
+		err = fmt.Errorf("err: rows affected %d", rowsAffected)
+	}
+
+	return err
+}
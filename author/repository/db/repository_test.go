@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestAuthorRepository_Fetch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+		AddRow(1, "Tolkien", now, now).
+		AddRow(2, "King", now, now).
+		AddRow(3, "Austen", now, now)
+
+	query := "SELECT id, name, created_at, updated_at FROM author " +
+		"WHERE \\(created_at > \\? OR \\(created_at = \\? AND id > \\?\\)\\) ORDER BY created_at ASC, id ASC LIMIT \\?"
+	mock.ExpectQuery(query).WithArgs(now, now, 7, 3).WillReturnRows(rows)
+	a := NewAuthorRepository(db)
+
+	list, info, err := a.Fetch(context.TODO(), domain.PageRequest{After: &domain.Position{Time: now, ID: 7}, Num: 2})
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.True(t, info.HasNext, "the extra row tells there is more")
+	assert.True(t, info.HasPrev)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorRepository_FetchRowError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+		AddRow(1, "Tolkien", now, now).
+		AddRow(2, "King", now, now).
+		RowError(1, errors.New("connection reset"))
+
+	mock.ExpectQuery("SELECT id, name, created_at, updated_at FROM author").WillReturnRows(rows)
+	a := NewAuthorRepository(db)
+
+	list, _, err := a.Fetch(context.TODO(), domain.PageRequest{Num: 10})
+	assert.EqualError(t, err, "connection reset")
+	assert.Nil(t, list, "a broken read is not a short page")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+			AddRow(1, "Tolkien", time.Now(), time.Now())
+
+		query := "SELECT id,name,created_at,updated_at FROM author WHERE id=\\?"
+		prep := mock.ExpectPrepare(query).WillBeClosed()
+		prep.ExpectQuery().WithArgs(1).WillReturnRows(rows)
+
+		a := NewAuthorRepository(db)
+		author, err := a.GetByID(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Tolkien", author.Name)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"})
+
+		prep := mock.ExpectPrepare("SELECT id,name,created_at,updated_at FROM author").WillBeClosed()
+		prep.ExpectQuery().WithArgs(2).WillReturnRows(rows)
+
+		a := NewAuthorRepository(db)
+		_, err := a.GetByID(context.TODO(), 2)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+}
+
+func TestAuthorRepository_GetByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+		AddRow(1, "Tolkien", time.Now(), time.Now()).
+		AddRow(3, "King", time.Now(), time.Now())
+
+	query := "SELECT id, name, created_at, updated_at FROM author WHERE id IN \\(\\?, \\?, \\?\\)"
+	mock.ExpectQuery(query).WithArgs(1, 2, 3).WillReturnRows(rows)
+
+	a := NewAuthorRepository(db)
+	authors, err := a.GetByIDs(context.TODO(), []int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, authors, 2)
+	assert.Equal(t, "King", authors[3].Name)
+	assert.NoError(t, mock.ExpectationsWereMet(), "one query for every id")
+}
+
+func TestAuthorRepository_Store(t *testing.T) {
+	now := time.Now()
+	au := &domain.Author{
+		Name:      "Tolkien",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "INSERT INTO author \\(name, created_at, updated_at\\) VALUES \\(\\?, \\?, \\?\\)"
+	prep := mock.ExpectPrepare(query).WillBeClosed()
+	prep.ExpectExec().WithArgs(au.Name, au.CreatedAt, au.UpdatedAt).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	a := NewAuthorRepository(db)
+
+	err = a.Store(context.TODO(), au)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), au.ID)
+}
+
+func TestAuthorRepository_Update(t *testing.T) {
+	au := &domain.Author{
+		ID:        7,
+		Name:      "Tolkien",
+		UpdatedAt: time.Now(),
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "UPDATE author SET name=\\?, updated_at=\\? WHERE id = \\?"
+	prep := mock.ExpectPrepare(query).WillBeClosed()
+	prep.ExpectExec().WithArgs(au.Name, au.UpdatedAt, au.ID).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	a := NewAuthorRepository(db)
+
+	err = a.Update(context.TODO(), au)
+	assert.NoError(t, err)
+}
+
+func TestAuthorRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "DELETE FROM author WHERE id = \\?"
+	prep := mock.ExpectPrepare(query).WillBeClosed()
+	prep.ExpectExec().WithArgs(7).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	a := NewAuthorRepository(db)
+
+	err = a.Delete(context.TODO(), 7)
+	assert.NoError(t, err)
+}
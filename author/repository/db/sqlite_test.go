@@ -0,0 +1,17 @@
+//go:build cgo
+
+package db
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"github.com/angelRaynov/clean-architecture/migration/migrationtest"
+	"testing"
+)
+
+func TestAuthorRepository_SQLiteContract(t *testing.T) {
+	repositorytest.AuthorRepository(t, func(t *testing.T) domain.AuthorRepository {
+		return NewAuthorRepositoryWithDialect(migrationtest.OpenSQLite(t), dialect.SQLite)
+	})
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/article/repository"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"sort"
+	"sync"
+)
+
+// authorRepo keeps authors in a map guarded by a RWMutex.
+type authorRepo struct {
+	mu      sync.RWMutex
+	authors map[int64]domain.Author
+	lastID  int64
+}
+
+func NewAuthorRepository() domain.AuthorRepository {
+	return &authorRepo{
+		authors: map[int64]domain.Author{},
+	}
+}
+
+func (a *authorRepo) Fetch(ctx context.Context, page domain.PageRequest) ([]domain.Author, domain.PageInfo, error) {
+	var by domain.Sort
+
+	a.mu.RLock()
+	res := make([]domain.Author, 0, len(a.authors))
+	for _, au := range a.authors {
+		pos := au.Position()
+		if (page.After == nil || by.Less(*page.After, pos)) &&
+			(page.Before == nil || by.Less(pos, *page.Before)) {
+			res = append(res, au)
+		}
+	}
+	a.mu.RUnlock()
+
+	// Pages before a cursor are read backwards.
+	sort.Slice(res, func(i, j int) bool {
+		if page.Before != nil {
+			return by.Less(res[j].Position(), res[i].Position())
+		}
+		return by.Less(res[i].Position(), res[j].Position())
+	})
+
+	if int64(len(res)) > page.Num+1 {
+		res = res[:page.Num+1]
+	}
+
+	res, info := repository.Window(res, page)
+	return res, info, nil
+}
+
+func (a *authorRepo) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	au, ok := a.authors[id]
+	if !ok {
+		return domain.Author{}, domain.ErrNotFound
+	}
+
+	return au, nil
+}
+
+func (a *authorRepo) GetByIDs(ctx context.Context, ids []int64) (map[int64]domain.Author, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	res := make(map[int64]domain.Author, len(ids))
+	for _, id := range ids {
+		if au, ok := a.authors[id]; ok {
+			res[id] = au
+		}
+	}
+
+	return res, nil
+}
+
+func (a *authorRepo) Update(ctx context.Context, au *domain.Author) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	existing, ok := a.authors[au.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	existing.Name = au.Name
+	existing.UpdatedAt = au.UpdatedAt
+	a.authors[au.ID] = existing
+
+	return nil
+}
+
+func (a *authorRepo) Store(ctx context.Context, au *domain.Author) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastID++
+	au.ID = a.lastID
+	a.authors[au.ID] = *au
+
+	return nil
+}
+
+func (a *authorRepo) Delete(ctx context.Context, id int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.authors[id]; !ok {
+		return domain.ErrNotFound
+	}
+
+	delete(a.authors, id)
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"testing"
+)
+
+func TestAuthorRepository_Contract(t *testing.T) {
+	repositorytest.AuthorRepository(t, func(t *testing.T) domain.AuthorRepository {
+		return NewAuthorRepository()
+	})
+}
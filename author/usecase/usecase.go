@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"time"
+)
+
+type authorUseCase struct {
+	authorRepo     domain.AuthorRepository
+	contextTimeout time.Duration
+	pageSize       domain.PageSize
+}
+
+func NewAuthorUseCase(ar domain.AuthorRepository, timeout time.Duration) domain.AuthorUseCase {
+	return NewAuthorUseCaseWithPageSize(ar, timeout, domain.DefaultPageSize)
+}
+
+// NewAuthorUseCaseWithPageSize returns a use case whose lists hold
+// pageSize.Default authors unless asked otherwise, and refuse to hold more
+// than pageSize.Max.
+func NewAuthorUseCaseWithPageSize(ar domain.AuthorRepository, timeout time.Duration, pageSize domain.PageSize) domain.AuthorUseCase {
+	return &authorUseCase{
+		authorRepo:     ar,
+		contextTimeout: timeout,
+		pageSize:       pageSize,
+	}
+}
+
+// Fetch lists authors oldest first. Authors are only sorted by created_at, so
+// page.Sort is dropped.
+func (a authorUseCase) Fetch(ctx context.Context, page domain.PageRequest) ([]domain.Author, domain.PageInfo, error) {
+	if err := checkRead(ctx); err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+
+	if page.After != nil && page.Before != nil {
+		return nil, domain.PageInfo{}, domain.NewError(domain.CodeBadInput, "a page cannot be both after and before a cursor")
+	}
+
+	num, err := a.pageSize.Resolve(page.Num)
+	if err != nil {
+		return nil, domain.PageInfo{}, err
+	}
+	page.Num = num
+	page.Sort = domain.Sort{}
+
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	return a.authorRepo.Fetch(ctx, page)
+}
+
+func (a authorUseCase) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	if err := checkRead(ctx); err != nil {
+		return domain.Author{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
+
+	return a.authorRepo.GetByID(ctx, id)
+}
+
+// Update renames an author. Only the author themselves or an admin may do so.
+func (a authorUseCase) Update(ctx context.Context, au *domain.Author) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+
+	defer cancel()
+
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !caller.IsAdmin() && (caller.AuthorID == 0 || caller.AuthorID != au.ID) {
+		return domain.ErrForbidden
+	}
+
+	existingAuthor, err := a.authorRepo.GetByID(ctx, au.ID)
+	if err != nil {
+		return err
+	}
+
+	au.CreatedAt = existingAuthor.CreatedAt
+	au.UpdatedAt = time.Now().Truncate(time.Second)
+
+	return a.authorRepo.Update(ctx, au)
+}
+
+// Store saves a new author. Only admins may create authors.
+func (a authorUseCase) Store(ctx context.Context, au *domain.Author) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+
+	defer cancel()
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	au.CreatedAt = now
+	au.UpdatedAt = now
+
+	return a.authorRepo.Store(ctx, au)
+}
+
+// Delete removes an author. Only admins may delete authors.
+func (a authorUseCase) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+
+	defer cancel()
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	_, err := a.authorRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return a.authorRepo.Delete(ctx, id)
+}
+
+// callerFrom returns the authenticated caller of a write. Anonymous requests
+// get domain.ErrUnauthorized, callers without the write scope
+// domain.ErrForbidden. Authors are covered by the article scopes.
+func callerFrom(ctx context.Context) (domain.Identity, error) {
+	caller, ok := domain.IdentityFromContext(ctx)
+	if !ok {
+		return domain.Identity{}, domain.ErrUnauthorized
+	}
+
+	if !caller.Allows(domain.ScopeWriteArticles) {
+		return domain.Identity{}, domain.ErrForbidden
+	}
+
+	return caller, nil
+}
+
+// checkRead lets anonymous callers read authors, since reads are public, but
+// holds callers with limited scopes, such as API keys, to the article read
+// scope.
+func checkRead(ctx context.Context) error {
+	caller, ok := domain.IdentityFromContext(ctx)
+	if ok && !caller.Allows(domain.ScopeReadArticles) {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// requireAdmin lets only admins through.
+func requireAdmin(ctx context.Context) error {
+	caller, err := callerFrom(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !caller.IsAdmin() {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
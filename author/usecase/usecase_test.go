@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func asAuthor(id int64) context.Context {
+	return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "author", AuthorID: id})
+}
+
+func asAdmin() context.Context {
+	return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "admin", Roles: []string{domain.RoleAdmin}})
+}
+
+func TestAuthorUseCase_Fetch(t *testing.T) {
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	mockListAuthor := []domain.Author{
+		{ID: 1, Name: "Tolkien"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		after := domain.Position{ID: 3}
+		mockAuthorRepo.On("Fetch", mock.Anything, domain.PageRequest{After: &after, Num: 10}).
+			Return(mockListAuthor, domain.PageInfo{HasNext: true}, nil).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		list, info, err := u.Fetch(context.TODO(), domain.PageRequest{After: &after, Sort: domain.Sort{Field: domain.SortTitle}})
+		assert.NoError(t, err)
+		assert.True(t, info.HasNext)
+		assert.Len(t, list, len(mockListAuthor))
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockAuthorRepo.On("Fetch", mock.Anything, mock.AnythingOfType("domain.PageRequest")).
+			Return(nil, domain.PageInfo{}, errors.New("unexpected error")).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		list, info, err := u.Fetch(context.TODO(), domain.PageRequest{Num: 5})
+		assert.Error(t, err)
+		assert.False(t, info.HasNext)
+		assert.Len(t, list, 0)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("num-out-of-range", func(t *testing.T) {
+		u := NewAuthorUseCaseWithPageSize(mockAuthorRepo, time.Second*2, domain.PageSize{Default: 10, Max: 20})
+
+		for _, num := range []int64{-1, 21} {
+			_, _, err := u.Fetch(context.TODO(), domain.PageRequest{Num: num})
+			assert.True(t, errors.Is(err, domain.ErrBadInput), "num %d", num)
+		}
+	})
+}
+
+func TestAuthorUseCase_Store(t *testing.T) {
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	mockAuthor := domain.Author{
+		Name: "Tolkien",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockAuthorRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Author")).Return(nil).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Store(asAdmin(), &mockAuthor)
+		assert.NoError(t, err)
+		assert.False(t, mockAuthor.CreatedAt.IsZero())
+		assert.Equal(t, mockAuthor.CreatedAt, mockAuthor.UpdatedAt)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Store(context.TODO(), &domain.Author{Name: "Tolkien"})
+		assert.Equal(t, domain.ErrUnauthorized, err)
+	})
+
+	t.Run("not-an-admin", func(t *testing.T) {
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Store(asAuthor(1), &domain.Author{Name: "Tolkien"})
+		assert.Equal(t, domain.ErrForbidden, err)
+	})
+}
+
+func TestAuthorUseCase_Update(t *testing.T) {
+	mockAuthorRepo := new(mocks.AuthorRepository)
+	created := time.Now().Add(-time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		mockAuthor := domain.Author{ID: 3, Name: "King"}
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(3)).
+			Return(domain.Author{ID: 3, Name: "Bachman", CreatedAt: created}, nil).Once()
+		mockAuthorRepo.On("Update", mock.Anything, &mockAuthor).Return(nil).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAuthor(3), &mockAuthor)
+		assert.NoError(t, err)
+		assert.Equal(t, created, mockAuthor.CreatedAt)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		mockAuthor := domain.Author{ID: 3, Name: "King"}
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(3)).
+			Return(domain.Author{ID: 3, Name: "Bachman", CreatedAt: created}, nil).Once()
+		mockAuthorRepo.On("Update", mock.Anything, &mockAuthor).Return(nil).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAdmin(), &mockAuthor)
+		assert.NoError(t, err)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("another-author", func(t *testing.T) {
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAuthor(2), &domain.Author{ID: 3, Name: "King"})
+		assert.Equal(t, domain.ErrForbidden, err)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Update(context.TODO(), &domain.Author{ID: 3, Name: "King"})
+		assert.Equal(t, domain.ErrUnauthorized, err)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(4)).
+			Return(domain.Author{}, domain.ErrNotFound).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Update(asAdmin(), &domain.Author{ID: 4, Name: "Nobody"})
+		assert.Equal(t, domain.ErrNotFound, err)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthorUseCase_Delete(t *testing.T) {
+	mockAuthorRepo := new(mocks.AuthorRepository)
+
+	t.Run("success", func(t *testing.T) {
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(3)).Return(domain.Author{ID: 3}, nil).Once()
+		mockAuthorRepo.On("Delete", mock.Anything, int64(3)).Return(nil).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Delete(asAdmin(), 3)
+		assert.NoError(t, err)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+
+	t.Run("not-an-admin", func(t *testing.T) {
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Delete(asAuthor(3), 3)
+		assert.Equal(t, domain.ErrForbidden, err)
+	})
+
+	t.Run("author-does-not-exist", func(t *testing.T) {
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(4)).Return(domain.Author{}, domain.ErrNotFound).Once()
+
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+
+		err := u.Delete(asAdmin(), 4)
+		assert.Error(t, err)
+		mockAuthorRepo.AssertExpectations(t)
+	})
+}
+
+// Authors have no scopes of their own: the article scopes cover them.
+func TestAuthorUseCase_Scopes(t *testing.T) {
+	withScopes := func(scopes ...string) context.Context {
+		return domain.WithIdentity(context.TODO(), domain.Identity{Subject: "api-key:1", AuthorID: 3, Scopes: scopes})
+	}
+
+	t.Run("write-only-key", func(t *testing.T) {
+		u := NewAuthorUseCase(new(mocks.AuthorRepository), time.Second*2)
+		ctx := withScopes(domain.ScopeWriteArticles)
+
+		_, err := u.GetByID(ctx, 3)
+		assert.Equal(t, domain.ErrForbidden, err)
+		_, _, err = u.Fetch(ctx, domain.PageRequest{})
+		assert.Equal(t, domain.ErrForbidden, err)
+	})
+
+	t.Run("read-only-key", func(t *testing.T) {
+		mockAuthorRepo := new(mocks.AuthorRepository)
+		mockAuthorRepo.On("GetByID", mock.Anything, int64(3)).Return(domain.Author{ID: 3}, nil).Once()
+		u := NewAuthorUseCase(mockAuthorRepo, time.Second*2)
+		ctx := withScopes(domain.ScopeReadArticles)
+
+		_, err := u.GetByID(ctx, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ErrForbidden, u.Update(ctx, &domain.Author{ID: 3, Name: "Martin"}))
+		mockAuthorRepo.AssertExpectations(t)
+	})
+}
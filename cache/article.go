@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"strconv"
+	"time"
+)
+
+// articleRepository reads articles by id through the cache. Lists and
+// searches go straight to the wrapped repository.
+type articleRepository struct {
+	domain.ArticleRepository
+	cache *readThrough
+}
+
+// ArticleRepository wraps next so GetByID is served from store for up to ttl.
+// Update, Store and Delete invalidate the article they write.
+func ArticleRepository(next domain.ArticleRepository, store Store, ttl time.Duration) domain.ArticleRepository {
+	return &articleRepository{
+		ArticleRepository: next,
+		cache:             &readThrough{store: store, ttl: ttl, repository: "article"},
+	}
+}
+
+func articleKey(id int64) string {
+	return "article:" + strconv.FormatInt(id, 10)
+}
+
+func (a *articleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+	var res domain.Article
+	err := a.cache.get(ctx, articleKey(id), &res, func(ctx context.Context) (interface{}, error) {
+		return a.ArticleRepository.GetByID(ctx, id)
+	})
+	if err != nil {
+		return domain.Article{}, err
+	}
+
+	return res, nil
+}
+
+// Update invalidates the article even when the write fails: a failed
+// precondition may stem from a stale cached copy.
+func (a *articleRepository) Update(ctx context.Context, ar *domain.Article, version time.Time) error {
+	defer a.cache.invalidate(ctx, articleKey(ar.ID))
+	return a.ArticleRepository.Update(ctx, ar, version)
+}
+
+// Store invalidates the new id in case the database reuses the id of a
+// deleted article whose entry is still cached.
+func (a *articleRepository) Store(ctx context.Context, ar *domain.Article) error {
+	if err := a.ArticleRepository.Store(ctx, ar); err != nil {
+		return err
+	}
+
+	a.cache.invalidate(ctx, articleKey(ar.ID))
+	return nil
+}
+
+func (a *articleRepository) Delete(ctx context.Context, id int64) error {
+	defer a.cache.invalidate(ctx, articleKey(id))
+	return a.ArticleRepository.Delete(ctx, id)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// authorRepository reads authors by id through the cache. Lists go straight
+// to the wrapped repository.
+type authorRepository struct {
+	domain.AuthorRepository
+	cache *readThrough
+}
+
+// AuthorRepository wraps next so GetByID and GetByIDs are served from store
+// for up to ttl. Update, Store and Delete invalidate the author they write.
+func AuthorRepository(next domain.AuthorRepository, store Store, ttl time.Duration) domain.AuthorRepository {
+	return &authorRepository{
+		AuthorRepository: next,
+		cache:            &readThrough{store: store, ttl: ttl, repository: "author"},
+	}
+}
+
+func authorKey(id int64) string {
+	return "author:" + strconv.FormatInt(id, 10)
+}
+
+func (a *authorRepository) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	var res domain.Author
+	err := a.cache.get(ctx, authorKey(id), &res, func(ctx context.Context) (interface{}, error) {
+		return a.AuthorRepository.GetByID(ctx, id)
+	})
+	if err != nil {
+		return domain.Author{}, err
+	}
+
+	return res, nil
+}
+
+// GetByIDs looks every id up in the cache and loads the misses with a single
+// call to the wrapped repository. Batches are not shared between concurrent
+// callers the way single misses are.
+func (a *authorRepository) GetByIDs(ctx context.Context, ids []int64) (map[int64]domain.Author, error) {
+	res := make(map[int64]domain.Author, len(ids))
+
+	var misses []int64
+	for _, id := range ids {
+		if data, ok := a.cache.lookup(ctx, authorKey(id)); ok {
+			var au domain.Author
+			if err := json.Unmarshal(data, &au); err == nil {
+				res[id] = au
+				continue
+			}
+		}
+		misses = append(misses, id)
+	}
+
+	if len(misses) == 0 {
+		return res, nil
+	}
+
+	writes := atomic.LoadUint64(&a.cache.writes)
+	loaded, err := a.AuthorRepository.GetByIDs(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	store := atomic.LoadUint64(&a.cache.writes) == writes
+	for id, au := range loaded {
+		res[id] = au
+		if !store {
+			continue
+		}
+		if data, err := json.Marshal(au); err == nil {
+			a.cache.set(ctx, authorKey(id), data)
+		}
+	}
+
+	return res, nil
+}
+
+func (a *authorRepository) Update(ctx context.Context, au *domain.Author) error {
+	defer a.cache.invalidate(ctx, authorKey(au.ID))
+	return a.AuthorRepository.Update(ctx, au)
+}
+
+// Store invalidates the new id in case the database reuses the id of a
+// deleted author whose entry is still cached.
+func (a *authorRepository) Store(ctx context.Context, au *domain.Author) error {
+	if err := a.AuthorRepository.Store(ctx, au); err != nil {
+		return err
+	}
+
+	a.cache.invalidate(ctx, authorKey(au.ID))
+	return nil
+}
+
+func (a *authorRepository) Delete(ctx context.Context, id int64) error {
+	defer a.cache.invalidate(ctx, authorKey(id))
+	return a.AuthorRepository.Delete(ctx, id)
+}
@@ -0,0 +1,136 @@
+// Package cache keeps repository reads in a Store, so popular articles and
+// authors are not read from the database on every request.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"golang.org/x/sync/singleflight"
+	"sync/atomic"
+	"time"
+)
+
+// Store keeps encoded values under string keys until their TTL runs out. LRU
+// keeps them in the memory of one replica. A Store backed by Redis or a
+// compatible server maps Get, Set and Delete onto GET, SET with EX and DEL,
+// and is shared by every replica.
+type Store interface {
+	// Get returns the value under key, or false when there is none.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// loadTimeout bounds a shared load. Loads do not stop when the reader that
+// started them goes away, since other readers may be waiting on them.
+const loadTimeout = 10 * time.Second
+
+// readThrough loads values through a Store as JSON. Concurrent misses of a
+// key share one load. A load that overlaps a write is returned but not
+// stored, so it cannot bring back the value the write replaced. The Store
+// failing only costs the cache: reads go to the repository and the error is
+// logged.
+type readThrough struct {
+	store      Store
+	ttl        time.Duration
+	repository string
+
+	group singleflight.Group
+	// writes counts invalidations; a load stores its value only if none
+	// happened since it started.
+	writes uint64
+}
+
+// get decodes the value under key into dst, calling load on a miss.
+func (r *readThrough) get(ctx context.Context, key string, dst interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	if data, ok := r.lookup(ctx, key); ok {
+		if err := json.Unmarshal(data, dst); err == nil {
+			return nil
+		}
+	}
+
+	ch := r.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(detached{ctx}, loadTimeout)
+		defer cancel()
+
+		writes := atomic.LoadUint64(&r.writes)
+
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if atomic.LoadUint64(&r.writes) == writes {
+			r.set(ctx, key, data)
+		}
+		return data, nil
+	})
+
+	// Every reader stops waiting when its own context is done.
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return res.Err
+		}
+		return json.Unmarshal(res.Val.([]byte), dst)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// detached keeps the values of its parent, such as the logger and the span,
+// but neither its deadline nor its cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+func (r *readThrough) lookup(ctx context.Context, key string) ([]byte, bool) {
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("reading the cache failed", "repository", r.repository, "key", key, "err", err)
+		return nil, false
+	}
+
+	return data, ok
+}
+
+func (r *readThrough) set(ctx context.Context, key string, data []byte) {
+	if err := r.store.Set(ctx, key, data, r.ttl); err != nil {
+		logging.FromContext(ctx).Warn("writing the cache failed", "repository", r.repository, "key", key, "err", err)
+	}
+}
+
+// invalidate drops keys after a write, and keeps loads already running from
+// storing what they read before it.
+func (r *readThrough) invalidate(ctx context.Context, keys ...string) {
+	atomic.AddUint64(&r.writes, 1)
+	for _, key := range keys {
+		r.group.Forget(key)
+	}
+
+	if err := r.store.Delete(ctx, keys...); err != nil {
+		logging.FromContext(ctx).Error("invalidating the cache failed", "repository", r.repository, "keys", keys, "err", err)
+	}
+}
@@ -0,0 +1,240 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	artMemory "github.com/angelRaynov/clean-architecture/article/repository/memory"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	"github.com/angelRaynov/clean-architecture/cache"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/angelRaynov/clean-architecture/domain/repositorytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArticleRepository_Contract(t *testing.T) {
+	repositorytest.ArticleRepository(t, func(t *testing.T) domain.ArticleRepository {
+		return cache.ArticleRepository(artMemory.NewArticleRepository(), cache.NewLRU(100), time.Minute)
+	})
+}
+
+func TestAuthorRepository_Contract(t *testing.T) {
+	repositorytest.AuthorRepository(t, func(t *testing.T) domain.AuthorRepository {
+		return cache.AuthorRepository(authMemory.NewAuthorRepository(), cache.NewLRU(100), time.Minute)
+	})
+}
+
+func TestArticleRepository_ReadThrough(t *testing.T) {
+	ctx := context.TODO()
+	article := domain.Article{ID: 1, Title: "It", Author: domain.Author{ID: 2}, UpdatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	next := new(mocks.ArticleRepository)
+	next.On("GetByID", mock.Anything, int64(1)).Return(article, nil).Twice()
+	next.On("GetByID", mock.Anything, int64(7)).Return(domain.Article{}, domain.ErrNotFound).Twice()
+	next.On("Update", mock.Anything, mock.Anything, article.UpdatedAt).Return(nil).Once()
+	next.On("Delete", mock.Anything, int64(1)).Return(nil).Once()
+	repo := cache.ArticleRepository(next, cache.NewLRU(10), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		res, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, article, res)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(ctx, 7)
+		assert.Equal(t, domain.ErrNotFound, err, "misses are not cached")
+	}
+
+	require.NoError(t, repo.Update(ctx, &article, article.UpdatedAt))
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err, "the update invalidated the article")
+
+	require.NoError(t, repo.Delete(ctx, 1))
+	next.On("GetByID", mock.Anything, int64(1)).Return(domain.Article{}, domain.ErrNotFound).Once()
+	_, err = repo.GetByID(ctx, 1)
+	assert.Equal(t, domain.ErrNotFound, err, "the delete invalidated the article")
+
+	next.AssertExpectations(t)
+}
+
+func TestArticleRepository_ConcurrentMissesShareALoad(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	next := new(mocks.ArticleRepository)
+	next.On("GetByID", mock.Anything, int64(1)).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(domain.Article{ID: 1, Title: "It"}, nil).Once()
+	repo := cache.ArticleRepository(next, cache.NewLRU(10), time.Minute)
+
+	var wg sync.WaitGroup
+	get := func() {
+		defer wg.Done()
+		res, err := repo.GetByID(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "It", res.Title)
+	}
+
+	wg.Add(1)
+	go get()
+	<-started
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go get()
+	}
+
+	// Give the other readers time to join the load in progress.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	next.AssertExpectations(t)
+}
+
+func TestArticleRepository_CancelledReaderDoesNotFailTheLoad(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+
+	next := new(mocks.ArticleRepository)
+	next.On("GetByID", mock.Anything, int64(1)).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-release
+			assert.NoError(t, args.Get(0).(context.Context).Err(), "the load outlives the reader that started it")
+		}).
+		Return(domain.Article{ID: 1, Title: "It"}, nil).Once()
+	repo := cache.ArticleRepository(next, cache.NewLRU(10), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := repo.GetByID(ctx, 1)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan domain.Article)
+	go func() {
+		res, err := repo.GetByID(context.TODO(), 1)
+		assert.NoError(t, err)
+		second <- res
+	}()
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-first, "the cancelled reader stops waiting")
+
+	close(release)
+	assert.Equal(t, "It", (<-second).Title)
+
+	next.AssertExpectations(t)
+}
+
+func TestArticleRepository_LoadOverlappingAWriteIsNotStored(t *testing.T) {
+	ctx := context.TODO()
+	started, release := make(chan struct{}), make(chan struct{})
+
+	next := new(mocks.ArticleRepository)
+	next.On("GetByID", mock.Anything, int64(1)).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(domain.Article{ID: 1, Title: "Old"}, nil).Once()
+	next.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	next.On("GetByID", mock.Anything, int64(1)).Return(domain.Article{ID: 1, Title: "New"}, nil).Once()
+	repo := cache.ArticleRepository(next, cache.NewLRU(10), time.Minute)
+
+	done := make(chan domain.Article)
+	go func() {
+		res, err := repo.GetByID(ctx, 1)
+		assert.NoError(t, err)
+		done <- res
+	}()
+
+	<-started
+	require.NoError(t, repo.Update(ctx, &domain.Article{ID: 1, Title: "New"}, time.Time{}))
+	close(release)
+	assert.Equal(t, "Old", (<-done).Title)
+
+	res, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "New", res.Title)
+
+	next.AssertExpectations(t)
+}
+
+// brokenStore fails every call, like a cache server that is down.
+type brokenStore struct{}
+
+var errUnavailable = errors.New("connection refused")
+
+func (brokenStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errUnavailable
+}
+
+func (brokenStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errUnavailable
+}
+
+func (brokenStore) Delete(context.Context, ...string) error {
+	return errUnavailable
+}
+
+func TestRepositories_BrokenStore(t *testing.T) {
+	ctx := context.TODO()
+	authors := cache.AuthorRepository(authMemory.NewAuthorRepository(), brokenStore{}, time.Minute)
+	articles := cache.ArticleRepository(artMemory.NewArticleRepository(), brokenStore{}, time.Minute)
+
+	au := domain.Author{Name: "King"}
+	require.NoError(t, authors.Store(ctx, &au))
+	ar := domain.Article{Title: "It", Author: au}
+	require.NoError(t, articles.Store(ctx, &ar))
+
+	res, err := articles.GetByID(ctx, ar.ID)
+	require.NoError(t, err, "reads fall back to the repository")
+	assert.Equal(t, "It", res.Title)
+
+	found, err := authors.GetByIDs(ctx, []int64{au.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "King", found[au.ID].Name)
+}
+
+func TestAuthorRepository_GetByIDs(t *testing.T) {
+	ctx := context.TODO()
+	king := domain.Author{ID: 1, Name: "King"}
+	austen := domain.Author{ID: 3, Name: "Austen"}
+
+	next := new(mocks.AuthorRepository)
+	next.On("GetByID", mock.Anything, int64(1)).Return(king, nil).Once()
+	next.On("GetByIDs", mock.Anything, []int64{2, 3}).Return(map[int64]domain.Author{3: austen}, nil).Once()
+	next.On("GetByIDs", mock.Anything, []int64{2}).Return(map[int64]domain.Author{}, nil).Once()
+	next.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	next.On("GetByIDs", mock.Anything, []int64{3}).Return(map[int64]domain.Author{3: austen}, nil).Once()
+	repo := cache.AuthorRepository(next, cache.NewLRU(10), time.Minute)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	res, err := repo.GetByIDs(ctx, []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]domain.Author{1: king, 3: austen}, res)
+
+	res, err = repo.GetByIDs(ctx, []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]domain.Author{1: king, 3: austen}, res, "only the missing author is looked up again")
+
+	require.NoError(t, repo.Update(ctx, &domain.Author{ID: 3, Name: "Austen"}))
+	res, err = repo.GetByIDs(ctx, []int64{1, 3})
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	next.AssertExpectations(t)
+}
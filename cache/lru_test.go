@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	get := func(key string) string {
+		v, ok, err := c.Get(ctx, key)
+		require.NoError(t, err)
+		if !ok {
+			return ""
+		}
+		return string(v)
+	}
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	assert.Equal(t, "1", get("a"), "reading a makes b the least recently used")
+
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, c.Len())
+	assert.Empty(t, get("b"))
+	assert.Equal(t, "1", get("a"))
+	assert.Equal(t, "3", get("c"))
+
+	require.NoError(t, c.Set(ctx, "a", []byte("4"), time.Second))
+	assert.Equal(t, "4", get("a"), "setting a key replaces its value")
+
+	now = now.Add(time.Second)
+	assert.Empty(t, get("a"), "the entry expired")
+	assert.Equal(t, "3", get("c"))
+	assert.Equal(t, 1, c.Len())
+
+	require.NoError(t, c.Delete(ctx, "c", "missing"))
+	assert.Empty(t, get("c"))
+	assert.Zero(t, c.Len())
+}
@@ -0,0 +1,441 @@
+// Package config loads the service configuration. Values are layered, each
+// source overriding the previous one: built-in defaults, a YAML or JSON file,
+// environment variables (optionally read from a .env file) and command line
+// flags.
+package config
+
+import (
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryDriver selects the in-memory repositories instead of a database.
+const MemoryDriver = "memory"
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Log       Log       `yaml:"log"`
+	CORS      CORS      `yaml:"cors"`
+	Auth      Auth      `yaml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Paging    Paging    `yaml:"paging"`
+	Cache     Cache     `yaml:"cache"`
+	// ContextTimeout bounds every use case call.
+	ContextTimeout time.Duration `yaml:"context_timeout"`
+}
+
+type Server struct {
+	Address string `yaml:"address"`
+	// ShutdownTimeout is how long in-flight requests may drain on shutdown
+	// before they are cancelled.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ReadinessDrainDelay is how long the server keeps serving after readiness
+	// starts failing on shutdown, so load balancers stop sending traffic before
+	// the listener closes.
+	ReadinessDrainDelay time.Duration `yaml:"readiness_drain_delay"`
+}
+
+type Database struct {
+	Driver   string `yaml:"driver"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	// Name is the database name, or the file path for SQLite.
+	Name string `yaml:"name"`
+	// SSLMode is the sslmode of PostgreSQL connections: disable, require,
+	// verify-ca or verify-full.
+	SSLMode string `yaml:"sslmode"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level"`
+}
+
+// Tracing exporters.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Tracing struct {
+	// Exporter is none, stdout or otlp.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the OTLP/HTTP collector URL, such as http://localhost:4318.
+	// Empty means the exporter's default or OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint string `yaml:"endpoint"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Traces
+	// started upstream follow the caller's sampling decision.
+	SampleRatio float64 `yaml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name"`
+}
+
+type CORS struct {
+	// AllowedOrigins lists the origins browsers may call from. An entry is an
+	// exact origin such as https://app.example.com, a pattern with one '*'
+	// such as https://*.example.com, or "*" for any origin.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	// AllowedHeaders are the request headers a browser may send; "*" allows
+	// any header.
+	AllowedHeaders []string `yaml:"allowed_headers"`
+	// ExposedHeaders are the response headers scripts may read.
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// Auth configures how bearer tokens are verified. HS256 tokens are checked
+// against HMACSecret, RS256 tokens against RSAPublicKeyFile or the keys of
+// JWKSFile; only the algorithms with a key configured are accepted.
+type Auth struct {
+	HMACSecret Secret `yaml:"hmac_secret"`
+	// RSAPublicKeyFile is a PEM encoded RSA public key.
+	RSAPublicKeyFile string `yaml:"rsa_public_key_file"`
+	// JWKSFile is a local JSON Web Key Set; tokens pick their key by kid.
+	JWKSFile string `yaml:"jwks_file"`
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+}
+
+// RateLimit configures the token buckets requests are taken from. Each
+// client, identified by its API key, user or IP address, gets a bucket per
+// route listed in Routes and one bucket shared by every other route. It is
+// off unless enabled, and the buckets live in each replica, so every replica
+// lets a client through at the full rate.
+type RateLimit struct {
+	Enabled bool  `yaml:"enabled"`
+	Limit   Limit `yaml:",inline"`
+	// Routes overrides Limit for single routes, keyed by method and route
+	// pattern such as "GET /articles/:id".
+	Routes map[string]Limit `yaml:"routes"`
+	// Authentication limits, by IP address, the requests presenting a token
+	// or an API key before it is checked, so credentials cannot be guessed
+	// at will.
+	Authentication Limit `yaml:"authentication"`
+	// TrustForwardedFor identifies anonymous clients by X-Forwarded-For or
+	// X-Real-IP. Only set it behind a proxy that overwrites those headers,
+	// otherwise clients pick their own key.
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+}
+
+// Limit lets Requests requests through per Period on average, and bursts of
+// up to Burst requests.
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+}
+
+func (l Limit) validate(name string) (errs ValidationError) {
+	if l.Requests <= 0 || l.Period <= 0 {
+		errs = append(errs, name+" must allow a positive number of requests per positive period")
+	}
+
+	if l.Burst <= 0 {
+		errs = append(errs, name+".burst must be positive")
+	}
+
+	return errs
+}
+
+// Paging bounds the size of list pages. Lists hold DefaultPageSize items
+// unless the client asks for up to MaxPageSize.
+type Paging struct {
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+	// CursorSecret signs the page cursors. Replicas must share it; when it is
+	// empty a random secret is used and cursors stop working on restart.
+	CursorSecret Secret `yaml:"cursor_secret"`
+	// CountCacheTTL is how long the total of numbered pages is reused before
+	// the articles are counted again; zero counts on every request.
+	CountCacheTTL time.Duration `yaml:"count_cache_ttl"`
+}
+
+// Cache configures the read-through cache in front of the article and author
+// repositories. It is off unless enabled. The cache lives in each replica, so
+// a replica sees the writes of the others, and serves their ETags, only once
+// its entries expire after TTL.
+type Cache struct {
+	Enabled bool          `yaml:"enabled"`
+	TTL     time.Duration `yaml:"ttl"`
+	// Size bounds the number of cached articles and authors; the least
+	// recently used are evicted first.
+	Size int `yaml:"size"`
+}
+
+// minHMACSecretLength is the size of a SHA-256 block key; shorter HS256
+// secrets can be brute forced offline from any token.
+const minHMACSecretLength = 32
+
+// Enabled reports whether any verification key is configured.
+func (a Auth) Enabled() bool {
+	return a.HMACSecret != "" || a.RSAPublicKeyFile != "" || a.JWKSFile != ""
+}
+
+// Default returns the configuration used for anything left unset.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Address:             ":9090",
+			ShutdownTimeout:     10 * time.Second,
+			ReadinessDrainDelay: 5 * time.Second,
+		},
+		Database: Database{
+			Driver:  string(dialect.MySQL),
+			Host:    "localhost",
+			Port:    "3306",
+			Name:    "article",
+			SSLMode: dialect.DefaultSSLMode,
+		},
+		Tracing: Tracing{
+			Exporter:    ExporterNone,
+			SampleRatio: 1,
+			ServiceName: "clean-architecture",
+		},
+		Log: Log{
+			Level: "info",
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", "X-API-Key", "X-Request-ID"},
+			ExposedHeaders: []string{"ETag", "X-Cursor", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Total-Count", "Link"},
+			MaxAge:         10 * time.Minute,
+		},
+		RateLimit: RateLimit{
+			Limit: Limit{
+				Requests: 300,
+				Period:   time.Minute,
+				Burst:    60,
+			},
+			Authentication: Limit{
+				Requests: 300,
+				Period:   time.Minute,
+				Burst:    60,
+			},
+		},
+		Paging: Paging{
+			DefaultPageSize: 10,
+			MaxPageSize:     100,
+			CountCacheTTL:   5 * time.Second,
+		},
+		Cache: Cache{
+			TTL:     30 * time.Second,
+			Size:    10000,
+		},
+		ContextTimeout: 2 * time.Second,
+	}
+}
+
+// InMemory reports whether the in-memory repositories were requested.
+func (d Database) InMemory() bool {
+	return strings.EqualFold(d.Driver, MemoryDriver)
+}
+
+// Dialect returns the SQL dialect of the configured driver.
+func (d Database) Dialect() (dialect.Dialect, error) {
+	return dialect.Parse(d.Driver)
+}
+
+// DSN returns the connection string, password included. Never log it.
+func (d Database) DSN() (string, error) {
+	dbDialect, err := d.Dialect()
+	if err != nil {
+		return "", err
+	}
+
+	return dbDialect.DSNWithSSLMode(d.User, d.Password.Value(), d.Host, d.Port, d.Name, d.SSLMode), nil
+}
+
+// Validate checks the whole configuration and reports every problem at once.
+func (c Config) Validate() error {
+	var errs ValidationError
+
+	if strings.TrimSpace(c.Server.Address) == "" {
+		errs = append(errs, "server.address is required")
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, "server.shutdown_timeout must be positive")
+	}
+
+	if c.Server.ReadinessDrainDelay < 0 {
+		errs = append(errs, "server.readiness_drain_delay cannot be negative")
+	}
+
+	if c.ContextTimeout <= 0 {
+		errs = append(errs, "context_timeout must be positive")
+	}
+
+	errs = append(errs, c.Database.validate()...)
+	errs = append(errs, c.Tracing.validate()...)
+	errs = append(errs, c.CORS.validate()...)
+
+	if c.RateLimit.Enabled {
+		errs = append(errs, c.RateLimit.Limit.validate("rate_limit")...)
+		errs = append(errs, c.RateLimit.Authentication.validate("rate_limit.authentication")...)
+		for route, limit := range c.RateLimit.Routes {
+			errs = append(errs, limit.validate(fmt.Sprintf("rate_limit.routes[%q]", route))...)
+		}
+	}
+
+	if c.Cache.Enabled {
+		if c.Cache.TTL <= 0 {
+			errs = append(errs, "cache.ttl must be positive")
+		}
+		if c.Cache.Size <= 0 {
+			errs = append(errs, "cache.size must be positive")
+		}
+	}
+
+	if c.Paging.DefaultPageSize <= 0 {
+		errs = append(errs, "paging.default_page_size must be positive")
+	}
+
+	if c.Paging.MaxPageSize < c.Paging.DefaultPageSize {
+		errs = append(errs, "paging.max_page_size cannot be less than paging.default_page_size")
+	}
+
+	if c.Paging.CursorSecret != "" && len(c.Paging.CursorSecret) < minHMACSecretLength {
+		errs = append(errs, fmt.Sprintf("paging.cursor_secret must be at least %d bytes long", minHMACSecretLength))
+	}
+
+	if c.Paging.CountCacheTTL < 0 {
+		errs = append(errs, "paging.count_cache_ttl cannot be negative")
+	}
+
+	if c.Auth.HMACSecret != "" && len(c.Auth.HMACSecret) < minHMACSecretLength {
+		errs = append(errs, fmt.Sprintf("auth.hmac_secret must be at least %d bytes long", minHMACSecretLength))
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("log.level %q must be debug, info, warn or error", c.Log.Level))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (d Database) validate() (errs ValidationError) {
+	if d.InMemory() {
+		return nil
+	}
+
+	dbDialect, err := d.Dialect()
+	if err != nil {
+		return ValidationError{"database.driver: " + err.Error()}
+	}
+
+	if d.Name == "" {
+		errs = append(errs, "database.name is required")
+	}
+
+	if dbDialect == dialect.SQLite {
+		return errs
+	}
+
+	if d.Host == "" {
+		errs = append(errs, "database.host is required")
+	}
+
+	if port, err := strconv.Atoi(d.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Sprintf("database.port %q is not a valid port", d.Port))
+	}
+
+	if d.User == "" {
+		errs = append(errs, "database.user is required")
+	}
+
+	if dbDialect == dialect.PostgreSQL && !dialect.ValidSSLMode(d.SSLMode) {
+		errs = append(errs, fmt.Sprintf("database.sslmode %q must be disable, require, verify-ca or verify-full", d.SSLMode))
+	}
+
+	return errs
+}
+
+func (t Tracing) validate() (errs ValidationError) {
+	switch t.Exporter {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Sprintf("tracing.exporter %q must be none, stdout or otlp", t.Exporter))
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errs = append(errs, "tracing.sample_ratio must be between 0 and 1")
+	}
+
+	if t.Exporter != ExporterNone && t.ServiceName == "" {
+		errs = append(errs, "tracing.service_name is required")
+	}
+
+	return errs
+}
+
+func (c CORS) validate() (errs ValidationError) {
+	for _, origin := range c.AllowedOrigins {
+		switch {
+		case origin == "*":
+			if c.AllowCredentials {
+				errs = append(errs, `cors.allowed_origins cannot be "*" when cors.allow_credentials is set`)
+			}
+		case strings.Count(origin, "*") > 1:
+			errs = append(errs, fmt.Sprintf("cors.allowed_origins %q may contain at most one '*'", origin))
+		case origin == "":
+			errs = append(errs, "cors.allowed_origins cannot contain an empty origin")
+		}
+	}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, "cors.max_age cannot be negative")
+	}
+
+	return errs
+}
+
+// ValidationError lists every invalid setting found by Validate.
+type ValidationError []string
+
+func (v ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(v, "; ")
+}
+
+// Secret holds a value that must not end up in logs. Formatting or encoding
+// it prints a placeholder; Value returns the real thing.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
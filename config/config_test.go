@@ -0,0 +1,295 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadLayersSources(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+server:
+  address: ":8080"
+database:
+  driver: postgres
+  host: db.internal
+  port: "5432"
+  user: from-file
+  password: file-secret
+  name: articles
+context_timeout: 5s
+`), 0o600))
+
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("DB_USER", "from-env")
+	t.Setenv("CTX_TIMEOUT", "3")
+
+	cfg, rest, err := Load([]string{"-db-host", "flag-host", "migrate", "up"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"migrate", "up"}, rest)
+	assert.Equal(t, ":8080", cfg.Server.Address)
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, "flag-host", cfg.Database.Host)
+	assert.Equal(t, "from-env", cfg.Database.User)
+	assert.Equal(t, "file-secret", cfg.Database.Password.Value())
+	assert.Equal(t, 3*time.Second, cfg.ContextTimeout)
+}
+
+func TestLoadJSONFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"database": {"driver": "memory"}, "context_timeout": "1500ms"}`), 0o600))
+
+	cfg, _, err := Load([]string{"-config", file})
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Database.InMemory())
+	assert.Equal(t, 1500*time.Millisecond, cfg.ContextTimeout)
+	assert.Equal(t, Default().Server.Address, cfg.Server.Address)
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("databse:\n  driver: memory\n"), 0o600))
+
+	_, _, err := Load([]string{"-config", file})
+	assert.Error(t, err)
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.env")
+	require.NoError(t, os.WriteFile(file, []byte("DB_DRIVER=memory\nSERVER_ADDRESS=:7070\n"), 0o600))
+	t.Cleanup(func() {
+		os.Unsetenv("DB_DRIVER")
+		os.Unsetenv("SERVER_ADDRESS")
+	})
+
+	cfg, _, err := Load([]string{"-env-file", file})
+	require.NoError(t, err)
+	assert.True(t, cfg.Database.InMemory())
+	assert.Equal(t, ":7070", cfg.Server.Address)
+
+	_, _, err = Load([]string{"-env-file", filepath.Join(t.TempDir(), "missing.env")})
+	assert.Error(t, err)
+}
+
+func TestValidateAggregatesErrors(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Address = ""
+	cfg.ContextTimeout = 0
+	cfg.Server.ReadinessDrainDelay = -time.Second
+	cfg.Database.Port = "http"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	verr, ok := err.(ValidationError)
+	require.True(t, ok)
+	assert.ElementsMatch(t, ValidationError{
+		"server.address is required",
+		"context_timeout must be positive",
+		"server.readiness_drain_delay cannot be negative",
+		`database.port "http" is not a valid port`,
+		"database.user is required",
+	}, verr)
+
+	cfg = Default()
+	cfg.Database.Driver = "oracle"
+	assert.EqualError(t, cfg.Validate(), `invalid configuration: database.driver: unsupported database driver "oracle"`)
+
+	cfg = Default()
+	cfg.Database.Driver = "postgres"
+	cfg.Database.User = "article"
+	cfg.Database.SSLMode = "prefer"
+	assert.EqualError(t, cfg.Validate(), `invalid configuration: database.sslmode "prefer" must be disable, require, verify-ca or verify-full`)
+
+	cfg = Default()
+	cfg.Database = Database{Driver: "sqlite", Name: "article.db"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadReportsBadValues(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("CTX_TIMEOUT", "soon")
+
+	_, _, err := Load([]string{"-ctx-timeout", "later"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CTX_TIMEOUT")
+	assert.Contains(t, err.Error(), "-ctx-timeout")
+}
+
+func TestSecretIsRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.User = "user"
+	cfg.Database.Password = "hunter2"
+
+	for _, out := range []string{
+		fmt.Sprintf("%v", cfg),
+		fmt.Sprintf("%+v", cfg),
+		fmt.Sprintf("%#v", cfg),
+		fmt.Sprint(cfg.Database.Password),
+	} {
+		assert.NotContains(t, out, "hunter2")
+		assert.Contains(t, out, "[REDACTED]")
+	}
+
+	raw, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "hunter2")
+
+	dsn, err := cfg.Database.DSN()
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "hunter2")
+}
+
+func TestTracingConfig(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	t.Setenv("TRACING_ENDPOINT", "http://collector:4318")
+
+	cfg, _, err := Load([]string{"-tracing-sample-ratio", "0.25"})
+	require.NoError(t, err)
+	assert.Equal(t, Tracing{
+		Exporter:    ExporterOTLP,
+		Endpoint:    "http://collector:4318",
+		SampleRatio: 0.25,
+		ServiceName: "clean-architecture",
+	}, cfg.Tracing)
+
+	_, _, err = Load([]string{"-tracing-exporter", "zipkin", "-tracing-sample-ratio", "2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tracing.exporter "zipkin"`)
+	assert.Contains(t, err.Error(), "tracing.sample_ratio")
+}
+
+func TestCORSConfig(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://*.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	cfg, _, err := Load([]string{"-cors-max-age", "60"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "https://*.example.com"}, cfg.CORS.AllowedOrigins)
+	assert.True(t, cfg.CORS.AllowCredentials)
+	assert.Equal(t, time.Minute, cfg.CORS.MaxAge)
+	assert.Equal(t, Default().CORS.AllowedMethods, cfg.CORS.AllowedMethods)
+
+	_, _, err = Load([]string{"-cors-allowed-origins", "*,https://*.*.example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cors.allowed_origins cannot be "*"`)
+	assert.Contains(t, err.Error(), "at most one '*'")
+}
+
+func TestAuthConfig(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+
+	cfg, _, err := Load(nil)
+	require.NoError(t, err)
+	assert.False(t, cfg.Auth.Enabled())
+
+	t.Setenv("AUTH_HMAC_SECRET", "too short")
+	_, _, err = Load([]string{"-auth-issuer", "https://issuer.example"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.hmac_secret must be at least 32 bytes long")
+	assert.NotContains(t, err.Error(), "too short")
+
+	t.Setenv("AUTH_HMAC_SECRET", "0123456789abcdef0123456789abcdef")
+	cfg, _, err = Load(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.Auth.Enabled())
+	assert.Equal(t, redacted, fmt.Sprint(cfg.Auth.HMACSecret))
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+database:
+  driver: memory
+rate_limit:
+  enabled: true
+  requests: 100
+  period: 1m
+  routes:
+    "GET /articles":
+      requests: 10
+      period: 1m
+      burst: 5
+`), 0o600))
+
+	t.Setenv("RATE_LIMIT_BURST", "20")
+	cfg, _, err := Load([]string{"-config", file})
+	require.NoError(t, err)
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, Limit{Requests: 100, Period: time.Minute, Burst: 20}, cfg.RateLimit.Limit)
+	assert.Equal(t, Limit{Requests: 10, Period: time.Minute, Burst: 5}, cfg.RateLimit.Routes["GET /articles"])
+
+	_, _, err = Load([]string{"-config", file, "-rate-limit-auth-burst", "0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate_limit.authentication.burst must be positive")
+
+	_, _, err = Load([]string{"-config", file, "-rate-limit-burst", "0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate_limit.burst must be positive")
+
+	_, _, err = Load([]string{"-config", file, "-rate-limit-enabled=false", "-rate-limit-burst", "0"})
+	assert.NoError(t, err, "a disabled limiter is not validated")
+
+	assert.False(t, Default().RateLimit.Enabled, "rate limiting is opt-in")
+}
+
+func TestPagingConfig(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("PAGE_SIZE_MAX", "50")
+
+	cfg, _, err := Load([]string{"-page-size-default", "20"})
+	require.NoError(t, err)
+	assert.Equal(t, Paging{DefaultPageSize: 20, MaxPageSize: 50, CountCacheTTL: 5 * time.Second}, cfg.Paging)
+
+	_, _, err = Load([]string{"-page-size-default", "60"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paging.max_page_size cannot be less than paging.default_page_size")
+
+	_, _, err = Load([]string{"-page-size-default", "0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paging.default_page_size must be positive")
+
+	_, _, err = Load([]string{"-page-cursor-secret", "short"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paging.cursor_secret must be at least 32 bytes long")
+
+	cfg, _, err = Load([]string{"-page-count-cache-ttl", "1m"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, cfg.Paging.CountCacheTTL)
+
+	_, _, err = Load([]string{"-page-count-cache-ttl", "-1s"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paging.count_cache_ttl cannot be negative")
+}
+
+func TestCacheConfig(t *testing.T) {
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("CACHE_TTL", "1m")
+
+	cfg, _, err := Load([]string{"-cache-size", "50"})
+	require.NoError(t, err)
+	assert.Equal(t, Cache{TTL: time.Minute, Size: 50}, cfg.Cache, "caching is opt-in")
+
+	cfg, _, err = Load([]string{"-cache-enabled=true", "-cache-size", "50"})
+	require.NoError(t, err)
+	assert.Equal(t, Cache{Enabled: true, TTL: time.Minute, Size: 50}, cfg.Cache)
+
+	_, _, err = Load([]string{"-cache-enabled=true", "-cache-size", "0", "-cache-ttl", "0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache.ttl must be positive")
+	assert.Contains(t, err.Error(), "cache.size must be positive")
+
+	_, _, err = Load([]string{"-cache-enabled=false", "-cache-size", "0"})
+	assert.NoError(t, err, "a disabled cache is not validated")
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// binding ties one setting to its environment variable and flag.
+type binding struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+func setString(field func(c *Config) *string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func setDuration(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := parseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func setInt(field func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setList(field func(c *Config) *[]string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
+	}
+}
+
+var bindings = []binding{
+	{"SERVER_ADDRESS", "addr", "address the HTTP server listens on", setString(func(c *Config) *string { return &c.Server.Address })},
+	{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long requests may drain on shutdown, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"READINESS_DRAIN_DELAY", "readiness-drain-delay", "how long to keep serving after readiness fails on shutdown, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.Server.ReadinessDrainDelay })},
+	{"DB_DRIVER", "db-driver", "mysql, postgres, sqlite or memory", setString(func(c *Config) *string { return &c.Database.Driver })},
+	{"DB_HOST", "db-host", "database host", setString(func(c *Config) *string { return &c.Database.Host })},
+	{"DB_PORT", "db-port", "database port", setString(func(c *Config) *string { return &c.Database.Port })},
+	{"DB_USER", "db-user", "database user", setString(func(c *Config) *string { return &c.Database.User })},
+	{"DB_PASSWORD", "db-password", "database password", func(c *Config, v string) error {
+		c.Database.Password = Secret(v)
+		return nil
+	}},
+	{"DB_NAME", "db-name", "database name, or file path for sqlite", setString(func(c *Config) *string { return &c.Database.Name })},
+	{"DB_SSLMODE", "db-sslmode", "sslmode of postgres connections: disable, require, verify-ca or verify-full", setString(func(c *Config) *string { return &c.Database.SSLMode })},
+	{"TRACING_EXPORTER", "tracing-exporter", "none, stdout or otlp", setString(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"TRACING_ENDPOINT", "tracing-endpoint", "OTLP/HTTP collector URL", setString(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{"TRACING_SAMPLE_RATIO", "tracing-sample-ratio", "share of new traces recorded, from 0 to 1", func(c *Config, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		c.Tracing.SampleRatio = ratio
+		return nil
+	}},
+	{"LOG_LEVEL", "log-level", "debug, info, warn or error", setString(func(c *Config) *string { return &c.Log.Level })},
+	{"CORS_ALLOWED_ORIGINS", "cors-allowed-origins", "comma separated origins allowed to call the API, such as https://*.example.com", setList(func(c *Config) *[]string { return &c.CORS.AllowedOrigins })},
+	{"CORS_ALLOWED_METHODS", "cors-allowed-methods", "comma separated methods allowed in cross-origin requests", setList(func(c *Config) *[]string { return &c.CORS.AllowedMethods })},
+	{"CORS_ALLOWED_HEADERS", "cors-allowed-headers", "comma separated request headers allowed in cross-origin requests", setList(func(c *Config) *[]string { return &c.CORS.AllowedHeaders })},
+	{"CORS_EXPOSED_HEADERS", "cors-exposed-headers", "comma separated response headers exposed to browsers", setList(func(c *Config) *[]string { return &c.CORS.ExposedHeaders })},
+	{"CORS_ALLOW_CREDENTIALS", "cors-allow-credentials", "whether cross-origin requests may carry credentials", func(c *Config, v string) error {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		c.CORS.AllowCredentials = allow
+		return nil
+	}},
+	{"CORS_MAX_AGE", "cors-max-age", "how long browsers may cache preflight responses, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.CORS.MaxAge })},
+	{"AUTH_HMAC_SECRET", "auth-hmac-secret", "secret verifying HS256 bearer tokens", func(c *Config, v string) error {
+		c.Auth.HMACSecret = Secret(v)
+		return nil
+	}},
+	{"AUTH_RSA_PUBLIC_KEY_FILE", "auth-rsa-public-key-file", "PEM file with the RSA public key verifying RS256 bearer tokens", setString(func(c *Config) *string { return &c.Auth.RSAPublicKeyFile })},
+	{"AUTH_JWKS_FILE", "auth-jwks-file", "JSON Web Key Set file with the keys verifying RS256 bearer tokens", setString(func(c *Config) *string { return &c.Auth.JWKSFile })},
+	{"AUTH_ISSUER", "auth-issuer", "required iss claim of bearer tokens", setString(func(c *Config) *string { return &c.Auth.Issuer })},
+	{"AUTH_AUDIENCE", "auth-audience", "required aud claim of bearer tokens", setString(func(c *Config) *string { return &c.Auth.Audience })},
+	{"RATE_LIMIT_ENABLED", "rate-limit-enabled", "whether requests are rate limited", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		c.RateLimit.Enabled = enabled
+		return nil
+	}},
+	{"RATE_LIMIT_REQUESTS", "rate-limit-requests", "requests a client may make per rate limit period", setInt(func(c *Config) *int { return &c.RateLimit.Limit.Requests })},
+	{"RATE_LIMIT_PERIOD", "rate-limit-period", "rate limit period, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.RateLimit.Limit.Period })},
+	{"RATE_LIMIT_BURST", "rate-limit-burst", "requests a client may make at once", setInt(func(c *Config) *int { return &c.RateLimit.Limit.Burst })},
+	{"RATE_LIMIT_AUTH_REQUESTS", "rate-limit-auth-requests", "requests presenting credentials an IP address may make per period", setInt(func(c *Config) *int { return &c.RateLimit.Authentication.Requests })},
+	{"RATE_LIMIT_AUTH_PERIOD", "rate-limit-auth-period", "period of the limit on requests presenting credentials, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.RateLimit.Authentication.Period })},
+	{"RATE_LIMIT_AUTH_BURST", "rate-limit-auth-burst", "requests presenting credentials an IP address may make at once", setInt(func(c *Config) *int { return &c.RateLimit.Authentication.Burst })},
+	{"PAGE_SIZE_DEFAULT", "page-size-default", "number of items in a list page unless the client asks otherwise", setInt(func(c *Config) *int { return &c.Paging.DefaultPageSize })},
+	{"PAGE_SIZE_MAX", "page-size-max", "largest number of items a client may ask for in a list page", setInt(func(c *Config) *int { return &c.Paging.MaxPageSize })},
+	{"PAGE_COUNT_CACHE_TTL", "page-count-cache-ttl", "how long the total of numbered pages is reused, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.Paging.CountCacheTTL })},
+	{"PAGE_CURSOR_SECRET", "page-cursor-secret", "secret signing page cursors, shared by every replica", func(c *Config, v string) error {
+		c.Paging.CursorSecret = Secret(v)
+		return nil
+	}},
+	{"CACHE_ENABLED", "cache-enabled", "whether articles and authors are cached", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		c.Cache.Enabled = enabled
+		return nil
+	}},
+	{"CACHE_TTL", "cache-ttl", "how long articles and authors stay cached, in seconds or as a duration", setDuration(func(c *Config) *time.Duration { return &c.Cache.TTL })},
+	{"CACHE_SIZE", "cache-size", "number of articles and authors the cache holds", setInt(func(c *Config) *int { return &c.Cache.Size })},
+	{"CTX_TIMEOUT", "ctx-timeout", "use case timeout, in seconds or as a duration such as 1500ms", setDuration(func(c *Config) *time.Duration { return &c.ContextTimeout })},
+}
+
+// parseDuration accepts a bare number of seconds, which is what CTX_TIMEOUT
+// has always held, or a Go duration string.
+func parseDuration(v string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return time.ParseDuration(v)
+}
+
+// Load builds the configuration from args, which are the command line
+// arguments without the program name. It returns the arguments left after
+// the flags, such as a subcommand.
+//
+// The file named by -config or CONFIG_FILE is read first, then environment
+// variables, then flags. A .env file is loaded into the environment when
+// present; -env-file points at another one, which must then exist.
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+
+	fset := flag.NewFlagSet("app", flag.ContinueOnError)
+	configFile := fset.String("config", "", "YAML or JSON configuration file (or CONFIG_FILE)")
+	envFile := fset.String("env-file", "", "file to load environment variables from (default .env when present)")
+	values := make([]*string, len(bindings))
+	for i, b := range bindings {
+		values[i] = fset.String(b.flag, "", b.usage+" (or "+b.env+")")
+	}
+
+	if err := fset.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	if err := loadEnvFile(*envFile); err != nil {
+		return cfg, nil, err
+	}
+
+	if *configFile == "" {
+		*configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	var errs ValidationError
+	for _, b := range bindings {
+		v, ok := os.LookupEnv(b.env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := b.set(&cfg, v); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", b.env, err))
+		}
+	}
+
+	setFlags := map[string]bool{}
+	fset.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	for i, b := range bindings {
+		if !setFlags[b.flag] {
+			continue
+		}
+		if err := b.set(&cfg, *values[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("-%s: %v", b.flag, err))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+	if len(errs) > 0 {
+		return cfg, nil, errs
+	}
+
+	return cfg, fset.Args(), nil
+}
+
+func loadEnvFile(path string) error {
+	if path != "" {
+		return godotenv.Load(path)
+	}
+
+	err := godotenv.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// loadFile decodes a YAML file into cfg. JSON is valid YAML, so JSON files
+// are read the same way. Durations are written like "2s".
+func loadFile(cfg *Config, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err = dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+// Package cursor turns list positions into opaque cursors and back. A cursor
+// is signed so clients cannot forge one pointing anywhere they like, and
+// versioned so its layout can change without misreading old cursors. The
+// signature also covers the list a cursor was issued for, so it is refused
+// by every other list.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"math"
+	"time"
+)
+
+// Cursor layouts, after the version byte and before the signature:
+//
+//	version1: created_at seconds (8), nanoseconds (4), id (8)
+//	version2: sort field (1), flags (1), id (8), then the created_at or
+//	          updated_at seconds (8) and nanoseconds (4), or the title
+//	version3: search score as IEEE 754 bits (8), id (8)
+//
+// version1 cursors come from lists that could only be sorted by created_at.
+// version3 cursors point into search results and are read by DecodeSearch
+// only.
+const (
+	version1 byte = 1
+	version2 byte = 2
+	version3 byte = 3
+
+	version1Size  = 1 + 8 + 4 + 8
+	version2Head  = 1 + 1 + 1 + 8
+	version3Size  = 1 + 8 + 8
+	signatureSize = 16
+	keySize       = 32
+
+	flagDesc byte = 1
+)
+
+var fieldCodes = map[domain.SortField]byte{
+	domain.SortCreatedAt: 1,
+	domain.SortUpdatedAt: 2,
+	domain.SortTitle:     3,
+}
+
+// ErrInvalid is returned for a cursor that was not issued by the codec, was
+// altered or comes from an unknown version.
+var ErrInvalid = domain.NewError(domain.CodeBadInput, "the cursor is invalid")
+
+// Codec encodes and decodes the cursors signed with its key for one list.
+type Codec struct {
+	key  []byte
+	list string
+}
+
+// NewCodec returns a codec signing with key. Without a key a random one is
+// used, so cursors stop working on restart and are not shared by replicas.
+func NewCodec(key []byte) *Codec {
+	if len(key) == 0 {
+		key = make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			// The system random source is broken; nothing can be signed safely.
+			panic("cursor: reading a random key: " + err.Error())
+		}
+	}
+
+	return &Codec{key: key}
+}
+
+// For returns a codec with the same key for the cursors of list, such as
+// "articles". Cursors of one list do not decode for another.
+func (c *Codec) For(list string) *Codec {
+	return &Codec{key: c.key, list: list}
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(c.list))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+// Encode returns the cursor of pos in a list sorted by s.
+func (c *Codec) Encode(s domain.Sort, pos domain.Position) string {
+	s = s.Normalize()
+
+	buf := make([]byte, version2Head, version2Head+12+len(pos.Title)+signatureSize)
+	buf[0] = version2
+	buf[1] = fieldCodes[s.Field]
+	if s.Desc {
+		buf[2] = flagDesc
+	}
+	binary.BigEndian.PutUint64(buf[3:], uint64(pos.ID))
+
+	if s.Field == domain.SortTitle {
+		buf = append(buf, pos.Title...)
+	} else {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(pos.Time.Unix()))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(pos.Time.Nanosecond()))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(append(buf, c.sign(buf)...))
+}
+
+// Decode returns the sort and the position cursor was issued for, or
+// ErrInvalid.
+func (c *Codec) Decode(cursor string) (domain.Sort, domain.Position, error) {
+	payload, err := c.verify(cursor)
+	if err != nil {
+		return domain.Sort{}, domain.Position{}, err
+	}
+
+	switch {
+	case payload[0] == version1 && len(payload) == version1Size:
+		pos := domain.Position{
+			Time: readTime(payload[1:]),
+			ID:   int64(binary.BigEndian.Uint64(payload[13:])),
+		}
+		return domain.Sort{Field: domain.SortCreatedAt}, pos, nil
+	case payload[0] == version2 && len(payload) >= version2Head:
+		return decodeVersion2(payload)
+	}
+
+	return domain.Sort{}, domain.Position{}, ErrInvalid
+}
+
+// EncodeSearch returns the cursor of pos in search results.
+func (c *Codec) EncodeSearch(pos domain.SearchPosition) string {
+	buf := make([]byte, 1, version3Size+signatureSize)
+	buf[0] = version3
+	buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(pos.Score))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(pos.ID))
+
+	return base64.RawURLEncoding.EncodeToString(append(buf, c.sign(buf)...))
+}
+
+// DecodeSearch returns the search position cursor was issued for, or
+// ErrInvalid.
+func (c *Codec) DecodeSearch(cursor string) (domain.SearchPosition, error) {
+	payload, err := c.verify(cursor)
+	if err != nil || payload[0] != version3 || len(payload) != version3Size {
+		return domain.SearchPosition{}, ErrInvalid
+	}
+
+	return domain.SearchPosition{
+		Score: math.Float64frombits(binary.BigEndian.Uint64(payload[1:])),
+		ID:    int64(binary.BigEndian.Uint64(payload[9:])),
+	}, nil
+}
+
+// verify checks the signature of cursor and returns its payload.
+func (c *Codec) verify(cursor string) ([]byte, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(buf) < 1+signatureSize {
+		return nil, ErrInvalid
+	}
+
+	payload, signature := buf[:len(buf)-signatureSize], buf[len(buf)-signatureSize:]
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return nil, ErrInvalid
+	}
+
+	return payload, nil
+}
+
+func decodeVersion2(payload []byte) (domain.Sort, domain.Position, error) {
+	s := domain.Sort{Desc: payload[2]&flagDesc != 0}
+	for field, code := range fieldCodes {
+		if code == payload[1] {
+			s.Field = field
+		}
+	}
+
+	pos := domain.Position{ID: int64(binary.BigEndian.Uint64(payload[3:]))}
+	value := payload[version2Head:]
+
+	switch {
+	case s.Field == domain.SortTitle:
+		pos.Title = string(value)
+	case s.Field != "" && len(value) == 12:
+		pos.Time = readTime(value)
+	default:
+		return domain.Sort{}, domain.Position{}, ErrInvalid
+	}
+
+	return s, pos, nil
+}
+
+func readTime(b []byte) time.Time {
+	sec := int64(binary.BigEndian.Uint64(b))
+	nsec := int64(binary.BigEndian.Uint32(b[8:]))
+
+	return time.Unix(sec, nsec).UTC()
+}
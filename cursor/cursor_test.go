@@ -0,0 +1,136 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+func TestRoundTrip(t *testing.T) {
+	c := NewCodec(key)
+	created := time.Date(2022, 3, 4, 10, 30, 0, 123456789, time.UTC)
+
+	tests := map[string]struct {
+		sort domain.Sort
+		pos  domain.Position
+	}{
+		"default":         {domain.Sort{Field: domain.SortCreatedAt}, domain.Position{Time: created, ID: 42}},
+		"updated at desc": {domain.Sort{Field: domain.SortUpdatedAt, Desc: true}, domain.Position{Time: created, ID: 7}},
+		"title":           {domain.Sort{Field: domain.SortTitle}, domain.Position{Title: "Ünïcode, 100%", ID: 3}},
+		"empty title":     {domain.Sort{Field: domain.SortTitle, Desc: true}, domain.Position{ID: 1}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, pos, err := c.Decode(c.Encode(tc.sort, tc.pos))
+			require.NoError(t, err)
+			assert.Equal(t, tc.sort, s)
+			assert.True(t, tc.pos.Time.Equal(pos.Time), "nanoseconds survive")
+			assert.Equal(t, tc.pos.Title, pos.Title)
+			assert.Equal(t, tc.pos.ID, pos.ID)
+		})
+	}
+
+	s, _, err := c.Decode(c.Encode(domain.Sort{}, domain.Position{ID: 1}))
+	require.NoError(t, err)
+	assert.Equal(t, domain.SortCreatedAt, s.Field, "the zero sort is created_at")
+}
+
+func TestDecodeVersion1(t *testing.T) {
+	c := NewCodec(key)
+	created := time.Date(2022, 3, 4, 10, 30, 0, 5, time.UTC)
+
+	payload := []byte{version1}
+	payload = binary.BigEndian.AppendUint64(payload, uint64(created.Unix()))
+	payload = binary.BigEndian.AppendUint32(payload, uint32(created.Nanosecond()))
+	payload = binary.BigEndian.AppendUint64(payload, 9)
+
+	s, pos, err := c.Decode(base64.RawURLEncoding.EncodeToString(append(payload, c.sign(payload)...)))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Sort{Field: domain.SortCreatedAt}, s)
+	assert.True(t, created.Equal(pos.Time))
+	assert.Equal(t, int64(9), pos.ID)
+}
+
+func TestDecodeRejects(t *testing.T) {
+	c := NewCodec(key)
+	encoded := c.Encode(domain.Sort{}, domain.Position{Time: time.Now(), ID: 7})
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), raw...)
+	tampered[version2Head-1] ^= 1
+
+	resign := func(payload []byte) string {
+		return base64.RawURLEncoding.EncodeToString(append(payload, c.sign(payload)...))
+	}
+	payload := raw[:len(raw)-signatureSize]
+
+	otherVersion := append([]byte(nil), payload...)
+	otherVersion[0] = 4
+
+	unknownField := append([]byte(nil), payload...)
+	unknownField[1] = 9
+
+	tests := map[string]string{
+		"empty":         "",
+		"not base64":    "%%%",
+		"truncated":     encoded[:len(encoded)-4],
+		"tampered id":   base64.RawURLEncoding.EncodeToString(tampered),
+		"other key":     NewCodec(nil).Encode(domain.Sort{}, domain.Position{ID: 7}),
+		"other version": resign(otherVersion),
+		"unknown field": resign(unknownField),
+		"short time":    resign(payload[:len(payload)-1]),
+	}
+
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := c.Decode(cursor)
+			assert.Equal(t, ErrInvalid, err)
+		})
+	}
+}
+
+func TestSearchRoundTrip(t *testing.T) {
+	c := NewCodec(key)
+	pos := domain.SearchPosition{Score: 0.060793, ID: 42}
+
+	decoded, err := c.DecodeSearch(c.EncodeSearch(pos))
+	require.NoError(t, err)
+	assert.Equal(t, pos, decoded, "the score survives bit for bit")
+
+	listCursor := c.Encode(domain.Sort{}, domain.Position{ID: 42})
+	for name, cursor := range map[string]string{
+		"list cursor": listCursor,
+		"other key":   NewCodec(nil).EncodeSearch(pos),
+		"not base64":  "%%%",
+	} {
+		_, err := c.DecodeSearch(cursor)
+		assert.Equal(t, ErrInvalid, err, name)
+	}
+
+	_, _, err = c.Decode(c.EncodeSearch(pos))
+	assert.Equal(t, ErrInvalid, err, "search cursors do not page lists")
+}
+
+func TestCursorsAreBoundToTheirList(t *testing.T) {
+	articles := NewCodec(key).For("articles")
+	authors := NewCodec(key).For("authors")
+	pos := domain.Position{Time: time.Now(), ID: 7}
+
+	_, _, err := articles.Decode(articles.Encode(domain.Sort{}, pos))
+	require.NoError(t, err)
+
+	_, _, err = authors.Decode(articles.Encode(domain.Sort{}, pos))
+	assert.Equal(t, ErrInvalid, err)
+
+	_, err = authors.DecodeSearch(articles.EncodeSearch(domain.SearchPosition{ID: 7}))
+	assert.Equal(t, ErrInvalid, err)
+}
@@ -0,0 +1,123 @@
+// Package http holds what the HTTP handlers of every entity share: the
+// envelope of list responses and the parsing of ids and page parameters.
+package http
+
+import (
+	"github.com/angelRaynov/clean-architecture/cursor"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/labstack/echo"
+	"net/http"
+	"strconv"
+)
+
+// errInvalidID is returned for an :id path parameter that is not a number.
+var errInvalidID = domain.NewError(domain.CodeBadInput, "the id must be an integer")
+
+// errInvalidNum is returned for a num query parameter that is not a number.
+var errInvalidNum = domain.NewError(domain.CodeBadInput, "num must be an integer")
+
+var errAfterAndBefore = domain.NewError(domain.CodeBadInput, "after and before cannot be used together")
+
+// Page is the body of list responses. NextCursor is also sent in the
+// X-Cursor header and HasMore reports whether it is set. NextCursor is passed
+// back as the after parameter and PrevCursor as the before parameter; each is
+// empty when there is nothing on that side. PageSize is the number of items
+// that was asked for. Numbered pages leave the cursors empty and set HasMore
+// when a later page exists.
+type Page struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor"`
+	PrevCursor string      `json:"prev_cursor"`
+	HasMore    bool        `json:"has_more"`
+	PageSize   int64       `json:"page_size"`
+}
+
+// NewPage returns the envelope of list, the page asked for with page, with
+// the cursors of its first and last items. position tells where an item is
+// in the list.
+func NewPage[T any](list []T, info domain.PageInfo, page domain.PageRequest, cursors *cursor.Codec, position func(T) domain.Position) Page {
+	body := Page{Data: list, PageSize: page.Num}
+	if len(list) > 0 {
+		if info.HasNext {
+			body.NextCursor = cursors.Encode(page.Sort, position(list[len(list)-1]))
+		}
+		if info.HasPrev {
+			body.PrevCursor = cursors.Encode(page.Sort, position(list[0]))
+		}
+	}
+
+	return body
+}
+
+// WritePage responds with page.
+func WritePage(ec echo.Context, page Page) error {
+	page.HasMore = page.NextCursor != ""
+
+	ec.Response().Header().Set(`X-Cursor`, page.NextCursor)
+	return ec.JSON(http.StatusOK, page)
+}
+
+// ParseID reads the :id path parameter.
+func ParseID(ec echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(ec.Param("id"), 10, 64)
+	if err != nil {
+		return 0, errInvalidID
+	}
+
+	return id, nil
+}
+
+// PageSize reads the num query parameter. A missing num means the default
+// page size.
+func PageSize(ec echo.Context, sizes domain.PageSize) (int64, error) {
+	var num int64
+	if numString := ec.QueryParam("num"); numString != "" {
+		var err error
+		num, err = strconv.ParseInt(numString, 10, 64)
+		if err != nil {
+			return 0, errInvalidNum
+		}
+	}
+
+	return sizes.Resolve(num)
+}
+
+// PageRequest reads the num, after and before query parameters. cursor is the
+// former name of after. A page next to a cursor has the sort the cursor was
+// issued for.
+func PageRequest(ec echo.Context, sizes domain.PageSize, cursors *cursor.Codec) (domain.PageRequest, error) {
+	num, err := PageSize(ec, sizes)
+	if err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	page := domain.PageRequest{Num: num}
+
+	after := ec.QueryParam("after")
+	if after == "" {
+		after = ec.QueryParam("cursor")
+	}
+	before := ec.QueryParam("before")
+
+	if after != "" && before != "" {
+		return domain.PageRequest{}, errAfterAndBefore
+	}
+
+	if after == "" && before == "" {
+		return page, nil
+	}
+
+	cursorSort, pos, err := cursors.Decode(after + before)
+	if err != nil {
+		return domain.PageRequest{}, err
+	}
+
+	page.Sort = cursorSort
+	if after != "" {
+		page.After = &pos
+	} else {
+		page.Before = &pos
+	}
+
+	return page, nil
+}
@@ -0,0 +1,172 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect identifies the SQL flavour spoken by the database behind a repository.
+type Dialect string
+
+const (
+	MySQL      Dialect = "mysql"
+	PostgreSQL Dialect = "postgres"
+	SQLite     Dialect = "sqlite"
+)
+
+// Parse maps a DB_DRIVER value to its dialect. An empty value means MySQL.
+func Parse(driver string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", "mysql":
+		return MySQL, nil
+	case "postgres", "postgresql", "pg":
+		return PostgreSQL, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// DriverName is the name the dialect's database/sql driver is registered under.
+func (d Dialect) DriverName() string {
+	switch d {
+	case PostgreSQL:
+		return "postgres"
+	case SQLite:
+		return "sqlite3"
+	default:
+		return "mysql"
+	}
+}
+
+// DefaultSSLMode is the sslmode of PostgreSQL connections unless configured:
+// the connection is encrypted but the server certificate is not verified.
+const DefaultSSLMode = "require"
+
+// ValidSSLMode reports whether the PostgreSQL driver accepts mode.
+func ValidSSLMode(mode string) bool {
+	switch mode {
+	case "disable", "require", "verify-ca", "verify-full":
+		return true
+	}
+
+	return false
+}
+
+// DSN builds a connection string for the dialect, connecting to PostgreSQL
+// with DefaultSSLMode. SQLite only uses name, which is the path of the
+// database file.
+func (d Dialect) DSN(user, password, host, port, name string) string {
+	return d.DSNWithSSLMode(user, password, host, port, name, DefaultSSLMode)
+}
+
+// DSNWithSSLMode is DSN with the sslmode of PostgreSQL connections. The
+// other dialects ignore it.
+func (d Dialect) DSNWithSSLMode(user, password, host, port, name, sslMode string) string {
+	switch d {
+	case PostgreSQL:
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(user, password),
+			Host:     host + ":" + port,
+			Path:     name,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return u.String()
+	case SQLite:
+		return "file:" + name + "?_foreign_keys=on&_loc=auto"
+	default:
+		cfg := mysql.NewConfig()
+		cfg.User = user
+		cfg.Passwd = password
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(host, port)
+		cfg.DBName = name
+		cfg.ParseTime = true
+		return cfg.FormatDSN()
+	}
+}
+
+// Rebind rewrites the ? placeholders used throughout the repositories into the
+// dialect's own bind variable syntax.
+func (d Dialect) Rebind(query string) string {
+	if d != PostgreSQL {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// Args prepares bind arguments for the dialect. SQLite keeps timestamps as
+// text and compares them as strings, so they are all written in UTC.
+func (d Dialect) Args(args ...interface{}) []interface{} {
+	if d != SQLite {
+		return args
+	}
+
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+
+	return args
+}
+
+// Like returns a case-insensitive LIKE condition on column that treats a
+// backslash as the escape character.
+func (d Dialect) Like(column string) string {
+	switch d {
+	case PostgreSQL:
+		return column + " ILIKE ?"
+	case SQLite:
+		return column + ` LIKE ? ESCAPE '\'`
+	default:
+		return column + " LIKE ?"
+	}
+}
+
+// ReturningID reports whether inserts must use RETURNING id because the driver
+// does not implement LastInsertId.
+func (d Dialect) ReturningID() bool {
+	return d == PostgreSQL
+}
+
+// IsUniqueViolation reports whether err was raised by a unique index.
+func (d Dialect) IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch d {
+	case PostgreSQL:
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	case SQLite:
+		// go-sqlite3 only defines its error type when built with cgo.
+		return strings.HasPrefix(err.Error(), "UNIQUE constraint failed")
+	default:
+		var mysqlErr *mysql.MySQLError
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+	}
+}
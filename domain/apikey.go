@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// API key scopes. ScopeAdmin implies the others. Anonymous callers may read
+// articles, but a key needs ScopeReadArticles to read them, including the
+// read a merge patch starts with. Authors exist to sign articles, so the
+// article scopes cover them too.
+const (
+	ScopeReadArticles  = "read:articles"
+	ScopeWriteArticles = "write:articles"
+	ScopeAdmin         = "admin"
+)
+
+// ValidScope reports whether scope is one of the scopes above.
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeReadArticles, ScopeWriteArticles, ScopeAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey lets a machine client call the API without interactive login. Only
+// a hash of the key is stored; the key itself is shown once, when it is
+// issued or rotated.
+type APIKey struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" validate:"required,max=200"`
+	// Prefix is the public start of the key. It finds the key on lookup and
+	// tells keys apart in listings.
+	Prefix string `json:"prefix"`
+	// Hash is the hex SHA-256 of the whole key.
+	Hash   string   `json:"-"`
+	Scopes []string `json:"scopes" validate:"required"`
+	// AuthorID is the author the key writes as; zero for keys that do not
+	// write articles of their own.
+	AuthorID   int64      `json:"author_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key was granted scope, directly or through
+// ScopeAdmin.
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Revoked reports whether the key can no longer be used.
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+type APIKeyUseCase interface {
+	Fetch(ctx context.Context) ([]APIKey, error)
+	// Issue stores key with a new secret and returns the secret.
+	Issue(ctx context.Context, key *APIKey) (string, error)
+	// Rotate replaces the secret of a key; the old one stops working at once.
+	Rotate(ctx context.Context, id int64) (APIKey, string, error)
+	Revoke(ctx context.Context, id int64) error
+	// Authenticate returns the caller a secret belongs to.
+	Authenticate(ctx context.Context, secret string) (Identity, error)
+}
+
+type APIKeyRepository interface {
+	Fetch(ctx context.Context) ([]APIKey, error)
+	GetByID(ctx context.Context, id int64) (APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	Store(ctx context.Context, key *APIKey) error
+	// Update saves the prefix, hash and revocation of a key.
+	Update(ctx context.Context, key *APIKey) error
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
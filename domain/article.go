@@ -5,29 +5,53 @@ import (
 	"time"
 )
 
+// Article is a piece of writing by an Author. AuthorMissing is set on
+// articles read back after their author was deleted; their Author only holds
+// the id.
 type Article struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	Author    Author
-	Content   string    `json:"content"`
-	UpdatedAt time.Time `json:"updated_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            int64     `json:"id"`
+	Title         string    `json:"title"`
+	Author        Author    `validate:"-"`
+	AuthorMissing bool      `json:"author_missing,omitempty"`
+	Content       string    `json:"content"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ArticleSearchResult struct {
+	Article Article `json:"article"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// Position returns the place of r in search results.
+func (r ArticleSearchResult) Position() SearchPosition {
+	return SearchPosition{Score: r.Score, ID: r.Article.ID}
 }
 
 type ArticleUseCase interface {
-	Fetch(ctx context.Context, cursor string, num int64) ([]Article, string, error)
+	Fetch(ctx context.Context, filter ArticleFilter, page PageRequest) ([]Article, PageInfo, error)
+	FetchByAuthor(ctx context.Context, authorID int64, page PageRequest) ([]Article, PageInfo, error)
+	FetchPage(ctx context.Context, filter ArticleFilter, page OffsetRequest) ([]Article, int64, error)
 	GetByID(ctx context.Context, id int64) (Article, error)
-	Update(ctx context.Context, ar *Article) error
+	Update(ctx context.Context, ar *Article, version time.Time) error
 	GetByTitle(ctx context.Context, title string) (Article, error)
+	Search(ctx context.Context, query string, after *SearchPosition, num int64) ([]ArticleSearchResult, PageInfo, error)
 	Store(context.Context, *Article) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type ArticleRepository interface {
-	Fetch(ctx context.Context, cursor string, num int64) (res []Article, nextCursor string, err error)
+	Fetch(ctx context.Context, filter ArticleFilter, page PageRequest) (res []Article, info PageInfo, err error)
+	FetchByAuthor(ctx context.Context, authorID int64, page PageRequest) (res []Article, info PageInfo, err error)
+	FetchOffset(ctx context.Context, filter ArticleFilter, page OffsetRequest) (res []Article, err error)
+	Count(ctx context.Context, filter ArticleFilter) (total int64, err error)
 	GetByID(ctx context.Context, id int64) (Article, error)
 	GetByTitle(ctx context.Context, title string) (Article, error)
-	Update(ctx context.Context, ar *Article) error
+	// Search returns the num results following after, or the first ones
+	// when after is nil.
+	Search(ctx context.Context, query string, after *SearchPosition, num int64) (res []ArticleSearchResult, info PageInfo, err error)
+	Update(ctx context.Context, ar *Article, version time.Time) error
 	Store(ctx context.Context, a *Article) error
 	Delete(ctx context.Context, id int64) error
 }
@@ -1,14 +1,39 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Author struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Position returns the place of au in author lists, which are sorted by
+// created_at and then id.
+func (au Author) Position() Position {
+	return Position{Time: au.CreatedAt, ID: au.ID}
+}
+
+type AuthorUseCase interface {
+	Fetch(ctx context.Context, page PageRequest) ([]Author, PageInfo, error)
+	GetByID(ctx context.Context, id int64) (Author, error)
+	Update(ctx context.Context, au *Author) error
+	Store(ctx context.Context, au *Author) error
+	Delete(ctx context.Context, id int64) error
 }
 
 type AuthorRepository interface {
+	// Fetch lists authors oldest first; page.Sort is ignored.
+	Fetch(ctx context.Context, page PageRequest) ([]Author, PageInfo, error)
 	GetByID(ctx context.Context, id int64) (Author, error)
+	// GetByIDs returns the authors with the given ids in a single lookup.
+	// Ids that match no author are left out of the map.
+	GetByIDs(ctx context.Context, ids []int64) (map[int64]Author, error)
+	Update(ctx context.Context, au *Author) error
+	Store(ctx context.Context, au *Author) error
+	Delete(ctx context.Context, id int64) error
 }
@@ -1,10 +1,107 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCode classifies a domain error. Delivery layers map codes to their own
+// status codes, and clients may rely on them.
+type ErrorCode string
+
+const (
+	CodeInternal           ErrorCode = "internal"
+	CodeNotFound           ErrorCode = "not_found"
+	CodeConflict           ErrorCode = "conflict"
+	CodeBadInput           ErrorCode = "bad_input"
+	CodeValidation         ErrorCode = "validation_failed"
+	CodePreconditionFailed ErrorCode = "precondition_failed"
+	// CodePreconditionRequired is returned for a write that does not say
+	// which version it replaces.
+	CodePreconditionRequired ErrorCode = "precondition_required"
+	CodeUnauthorized         ErrorCode = "unauthorized"
+	CodeForbidden            ErrorCode = "forbidden"
+	CodeRateLimited          ErrorCode = "rate_limited"
+)
+
+// Error is the error type returned by the domain layers. Message is safe to
+// show to clients; Err is the underlying cause, kept for logs only.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Fields lists the invalid fields of a CodeValidation error.
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError explains why one field of a request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
 
 var (
-	ErrInternalServerError = errors.New("internal server error")
-	ErrNotFound            = errors.New("the requested item is not found")
-	ErrConflict            = errors.New("this item already exist")
-	ErrBadInput            = errors.New("invalid parameter")
+	ErrInternalServerError = &Error{Code: CodeInternal, Message: "internal server error"}
+	ErrNotFound            = &Error{Code: CodeNotFound, Message: "the requested item is not found"}
+	ErrConflict            = &Error{Code: CodeConflict, Message: "this item already exist"}
+	ErrBadInput            = &Error{Code: CodeBadInput, Message: "invalid parameter"}
+	ErrPreconditionFailed  = &Error{Code: CodePreconditionFailed, Message: "the item has been modified since it was last read"}
+	ErrUnauthorized        = &Error{Code: CodeUnauthorized, Message: "authentication is required"}
+	ErrForbidden           = &Error{Code: CodeForbidden, Message: "you are not allowed to change this item"}
+	ErrRateLimited         = &Error{Code: CodeRateLimited, Message: "too many requests, retry later"}
 )
+
+// NewError returns an error with code and a client-facing message.
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WrapError returns an error with code and message that keeps err as its
+// cause.
+func WrapError(code ErrorCode, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// NewValidationError reports the invalid fields of a request.
+func NewValidationError(fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Message: "the request has invalid fields", Fields: fields}
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if len(e.Fields) > 0 {
+		parts := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			parts[i] = f.Field + " " + f.Message
+		}
+		msg += ": " + strings.Join(parts, ", ")
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is match any *Error with the same code, so
+// errors.Is(err, ErrNotFound) holds for every not found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ErrorCodeOf returns the code of the first *Error in err's chain, or
+// CodeInternal when there is none.
+func ErrorCodeOf(err error) ErrorCode {
+	var de *Error
+	if errors.As(err, &de) {
+		return de.Code
+	}
+
+	return CodeInternal
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "context"
+
+// RoleAdmin may update and delete any article.
+const RoleAdmin = "admin"
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	// Subject identifies the caller to the token issuer.
+	Subject string
+	// AuthorID is the author the caller writes as; zero when the caller is
+	// not an author, such as an admin service account.
+	AuthorID int64
+	Roles    []string
+	// Scopes limits what the caller may do, for API keys. Nil means no
+	// limit beyond the roles, as for users signing in with a token.
+	Scopes []string
+}
+
+// HasRole reports whether the caller was granted role.
+func (i Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAdmin reports whether the caller has RoleAdmin.
+func (i Identity) IsAdmin() bool {
+	return i.HasRole(RoleAdmin)
+}
+
+// Allows reports whether the caller's scopes permit scope.
+func (i Identity) Allows(scope string) bool {
+	if i.Scopes == nil {
+		return true
+	}
+
+	for _, s := range i.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the authenticated caller.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the caller stored by WithIdentity, if any.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
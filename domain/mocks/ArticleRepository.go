@@ -7,6 +7,8 @@ import (
 
 	domain "github.com/angelRaynov/clean-architecture/domain"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ArticleRepository is an autogenerated mock type for the ArticleRepository type
@@ -14,6 +16,27 @@ type ArticleRepository struct {
 	mock.Mock
 }
 
+// Count provides a mock function with given fields: ctx, filter
+func (_m *ArticleRepository) Count(ctx context.Context, filter domain.ArticleFilter) (int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ArticleFilter) int64); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ArticleFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, id
 func (_m *ArticleRepository) Delete(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
@@ -28,29 +51,29 @@ func (_m *ArticleRepository) Delete(ctx context.Context, id int64) error {
 	return r0
 }
 
-// Fetch provides a mock function with given fields: ctx, cursor, num
-func (_m *ArticleRepository) Fetch(ctx context.Context, cursor string, num int64) ([]domain.Article, string, error) {
-	ret := _m.Called(ctx, cursor, num)
+// Fetch provides a mock function with given fields: ctx, filter, page
+func (_m *ArticleRepository) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	ret := _m.Called(ctx, filter, page)
 
 	var r0 []domain.Article
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) []domain.Article); ok {
-		r0 = rf(ctx, cursor, num)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ArticleFilter, domain.PageRequest) []domain.Article); ok {
+		r0 = rf(ctx, filter, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Article)
 		}
 	}
 
-	var r1 string
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) string); ok {
-		r1 = rf(ctx, cursor, num)
+	var r1 domain.PageInfo
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ArticleFilter, domain.PageRequest) domain.PageInfo); ok {
+		r1 = rf(ctx, filter, page)
 	} else {
-		r1 = ret.Get(1).(string)
+		r1 = ret.Get(1).(domain.PageInfo)
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, int64) error); ok {
-		r2 = rf(ctx, cursor, num)
+	if rf, ok := ret.Get(2).(func(context.Context, domain.ArticleFilter, domain.PageRequest) error); ok {
+		r2 = rf(ctx, filter, page)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -58,6 +81,59 @@ func (_m *ArticleRepository) Fetch(ctx context.Context, cursor string, num int64
 	return r0, r1, r2
 }
 
+// FetchByAuthor provides a mock function with given fields: ctx, authorID, page
+func (_m *ArticleRepository) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) ([]domain.Article, domain.PageInfo, error) {
+	ret := _m.Called(ctx, authorID, page)
+
+	var r0 []domain.Article
+	if rf, ok := ret.Get(0).(func(context.Context, int64, domain.PageRequest) []domain.Article); ok {
+		r0 = rf(ctx, authorID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Article)
+		}
+	}
+
+	var r1 domain.PageInfo
+	if rf, ok := ret.Get(1).(func(context.Context, int64, domain.PageRequest) domain.PageInfo); ok {
+		r1 = rf(ctx, authorID, page)
+	} else {
+		r1 = ret.Get(1).(domain.PageInfo)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, domain.PageRequest) error); ok {
+		r2 = rf(ctx, authorID, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FetchOffset provides a mock function with given fields: ctx, filter, page
+func (_m *ArticleRepository) FetchOffset(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) ([]domain.Article, error) {
+	ret := _m.Called(ctx, filter, page)
+
+	var r0 []domain.Article
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ArticleFilter, domain.OffsetRequest) []domain.Article); ok {
+		r0 = rf(ctx, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ArticleFilter, domain.OffsetRequest) error); ok {
+		r1 = rf(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *ArticleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
 	ret := _m.Called(ctx, id)
@@ -100,6 +176,36 @@ func (_m *ArticleRepository) GetByTitle(ctx context.Context, title string) (doma
 	return r0, r1
 }
 
+// Search provides a mock function with given fields: ctx, query, after, num
+func (_m *ArticleRepository) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) ([]domain.ArticleSearchResult, domain.PageInfo, error) {
+	ret := _m.Called(ctx, query, after, num)
+
+	var r0 []domain.ArticleSearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.SearchPosition, int64) []domain.ArticleSearchResult); ok {
+		r0 = rf(ctx, query, after, num)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ArticleSearchResult)
+		}
+	}
+
+	var r1 domain.PageInfo
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.SearchPosition, int64) domain.PageInfo); ok {
+		r1 = rf(ctx, query, after, num)
+	} else {
+		r1 = ret.Get(1).(domain.PageInfo)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, *domain.SearchPosition, int64) error); ok {
+		r2 = rf(ctx, query, after, num)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Store provides a mock function with given fields: ctx, a
 func (_m *ArticleRepository) Store(ctx context.Context, a *domain.Article) error {
 	ret := _m.Called(ctx, a)
@@ -114,13 +220,13 @@ func (_m *ArticleRepository) Store(ctx context.Context, a *domain.Article) error
 	return r0
 }
 
-// Update provides a mock function with given fields: ctx, ar
-func (_m *ArticleRepository) Update(ctx context.Context, ar *domain.Article) error {
-	ret := _m.Called(ctx, ar)
+// Update provides a mock function with given fields: ctx, ar, version
+func (_m *ArticleRepository) Update(ctx context.Context, ar *domain.Article, version time.Time) error {
+	ret := _m.Called(ctx, ar, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.Article) error); ok {
-		r0 = rf(ctx, ar)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Article, time.Time) error); ok {
+		r0 = rf(ctx, ar, version)
 	} else {
 		r0 = ret.Error(0)
 	}
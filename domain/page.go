@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// PageSize bounds the number of items a list returns at once.
+type PageSize struct {
+	// Default is used when the client does not ask for a size.
+	Default int64
+	Max     int64
+}
+
+// DefaultPageSize is used unless the page sizes are configured.
+var DefaultPageSize = PageSize{Default: 10, Max: 100}
+
+// Resolve returns the number of items to fetch when num were asked for: the
+// default for zero, num itself when it is between 1 and Max, and a bad input
+// error otherwise.
+func (p PageSize) Resolve(num int64) (int64, error) {
+	if num == 0 {
+		return p.Default, nil
+	}
+
+	if num < 0 || num > p.Max {
+		return 0, NewError(CodeBadInput, fmt.Sprintf("num must be between 1 and %d", p.Max))
+	}
+
+	return num, nil
+}
+
+// SortField names an article field lists can be sorted by.
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+	// SortTitle follows the collation of the database; the in-memory
+	// repository compares bytes.
+	SortTitle SortField = "title"
+)
+
+// Valid reports whether lists can be sorted by f.
+func (f SortField) Valid() bool {
+	switch f {
+	case SortCreatedAt, SortUpdatedAt, SortTitle:
+		return true
+	}
+
+	return false
+}
+
+// Sort is the order of a list: by Field, then by id to break ties, both
+// ascending unless Desc is set. The zero Sort is oldest first.
+type Sort struct {
+	Field SortField
+	Desc  bool
+}
+
+// Normalize returns s with the default field filled in.
+func (s Sort) Normalize() Sort {
+	if s.Field == "" {
+		s.Field = SortCreatedAt
+	}
+
+	return s
+}
+
+// Position is the place of an article in a sorted list: the value of the
+// sort field, Time for created_at and updated_at or Title for title, and the
+// id. Cursors carry it from one request to the next.
+type Position struct {
+	Time  time.Time
+	Title string
+	ID    int64
+}
+
+// SearchPosition is the place of a result in search results, which are
+// ordered by descending score and then by id.
+type SearchPosition struct {
+	Score float64
+	ID    int64
+}
+
+// Position returns the position of ar in lists sorted by s.
+func (s Sort) Position(ar Article) Position {
+	pos := Position{ID: ar.ID}
+	switch s.Normalize().Field {
+	case SortUpdatedAt:
+		pos.Time = ar.UpdatedAt
+	case SortTitle:
+		pos.Title = ar.Title
+	default:
+		pos.Time = ar.CreatedAt
+	}
+
+	return pos
+}
+
+// Less reports whether p comes before q in lists sorted by s.
+func (s Sort) Less(p, q Position) bool {
+	if s.Desc {
+		p, q = q, p
+	}
+
+	switch {
+	case s.Normalize().Field == SortTitle && p.Title != q.Title:
+		return p.Title < q.Title
+	case !p.Time.Equal(q.Time):
+		return p.Time.Before(q.Time)
+	}
+
+	return p.ID < q.ID
+}
+
+// PageRequest selects a page of Num items in the Sort order. The page starts
+// right after After or, to page backwards, ends right before Before; at most
+// one of them is set.
+type PageRequest struct {
+	After  *Position
+	Before *Position
+	Num    int64
+	Sort   Sort
+}
+
+// OffsetRequest selects the Page-th page, counting from 1, of PerPage items
+// in the Sort order. Unlike cursors, numbered pages shift when articles are
+// added or removed.
+type OffsetRequest struct {
+	Page    int64
+	PerPage int64
+	Sort    Sort
+}
+
+// Offset returns the number of items before the page.
+func (r OffsetRequest) Offset() int64 {
+	return (r.Page - 1) * r.PerPage
+}
+
+// PageInfo tells whether there are items on either side of a page.
+type PageInfo struct {
+	HasNext bool
+	HasPrev bool
+}
+
+// ArticleFilter narrows a list of articles; zero fields match everything.
+// The From times are inclusive and the To times exclusive. TitlePrefix
+// ignores case.
+type ArticleFilter struct {
+	AuthorID    int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	TitlePrefix string
+}
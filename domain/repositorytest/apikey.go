@@ -0,0 +1,128 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// APIKeyRepository runs the contract against repositories created by
+// newRepository, which is called once per subtest.
+func APIKeyRepository(t *testing.T, newRepository func(t *testing.T) domain.APIKeyRepository) {
+	ctx := context.TODO()
+
+	t.Run("store-and-get", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAPIKeys(t, repo, 2)
+
+		res, err := repo.GetByID(ctx, stored[1].ID)
+		require.NoError(t, err)
+		assertSameAPIKey(t, stored[1], res)
+
+		res, err = repo.GetByPrefix(ctx, stored[0].Prefix)
+		require.NoError(t, err)
+		assertSameAPIKey(t, stored[0], res)
+
+		all, err := repo.Fetch(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, stored[0].ID, all[0].ID)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		_, err := repo.GetByID(ctx, 4242)
+		assert.Equal(t, domain.ErrNotFound, err)
+
+		_, err = repo.GetByPrefix(ctx, "missing")
+		assert.Equal(t, domain.ErrNotFound, err)
+
+		assert.Equal(t, domain.ErrNotFound, repo.TouchLastUsed(ctx, 4242, baseTime))
+	})
+
+	t.Run("prefix-is-unique", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAPIKeys(t, repo, 2)
+
+		dup := domain.APIKey{Name: "dup", Prefix: stored[0].Prefix, Hash: stored[0].Hash, Scopes: []string{domain.ScopeAdmin}, CreatedAt: baseTime}
+		assert.Equal(t, domain.ErrConflict, repo.Store(ctx, &dup))
+
+		changed := stored[1]
+		changed.Prefix = stored[0].Prefix
+		assert.Equal(t, domain.ErrConflict, repo.Update(ctx, &changed))
+	})
+
+	t.Run("update-and-touch", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAPIKeys(t, repo, 1)[0]
+
+		revokedAt := baseTime.Add(time.Hour)
+		changed := stored
+		changed.Prefix = "rotated"
+		changed.Hash = fmt.Sprintf("%064d", 7)
+		changed.RevokedAt = &revokedAt
+		require.NoError(t, repo.Update(ctx, &changed))
+
+		usedAt := baseTime.Add(2 * time.Hour)
+		require.NoError(t, repo.TouchLastUsed(ctx, stored.ID, usedAt))
+
+		res, err := repo.GetByPrefix(ctx, "rotated")
+		require.NoError(t, err)
+		changed.LastUsedAt = &usedAt
+		assertSameAPIKey(t, changed, res)
+
+		missing := changed
+		missing.ID = 4242
+		missing.Prefix = "missing"
+		assert.Equal(t, domain.ErrNotFound, repo.Update(ctx, &missing))
+	})
+}
+
+func seedAPIKeys(t *testing.T, repo domain.APIKeyRepository, n int) []domain.APIKey {
+	res := make([]domain.APIKey, 0, n)
+	for i := 0; i < n; i++ {
+		k := domain.APIKey{
+			Name:      fmt.Sprintf("Key %d", i),
+			Prefix:    fmt.Sprintf("prefix%d", i),
+			Hash:      fmt.Sprintf("%064d", i),
+			Scopes:    []string{domain.ScopeReadArticles, domain.ScopeWriteArticles},
+			AuthorID:  int64(i + 1),
+			CreatedAt: baseTime.Add(time.Duration(i) * time.Minute),
+		}
+		require.NoError(t, repo.Store(context.TODO(), &k))
+		require.NotZero(t, k.ID)
+		res = append(res, k)
+	}
+
+	return res
+}
+
+func assertSameAPIKey(t *testing.T, expected, actual domain.APIKey) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Prefix, actual.Prefix)
+	assert.Equal(t, expected.Hash, actual.Hash)
+	assert.Equal(t, expected.Scopes, actual.Scopes)
+	assert.Equal(t, expected.AuthorID, actual.AuthorID)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at %s != %s", expected.CreatedAt, actual.CreatedAt)
+	assertSameTime(t, "last_used_at", expected.LastUsedAt, actual.LastUsedAt)
+	assertSameTime(t, "revoked_at", expected.RevokedAt, actual.RevokedAt)
+}
+
+func assertSameTime(t *testing.T, name string, expected, actual *time.Time) {
+	t.Helper()
+
+	if expected == nil || actual == nil {
+		assert.Equal(t, expected == nil, actual == nil, "%s %v != %v", name, expected, actual)
+		return
+	}
+
+	assert.True(t, expected.Equal(*actual), "%s %s != %s", name, expected, actual)
+}
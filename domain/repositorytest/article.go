@@ -0,0 +1,421 @@
+// Package repositorytest holds the behaviour every implementation of the
+// domain repositories must share. Backends run it from their own tests by
+// passing a factory that returns an empty repository.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// baseTime has no sub-second part so it survives DATETIME columns unchanged.
+var baseTime = time.Date(2022, time.March, 4, 10, 30, 0, 0, time.UTC)
+
+// ArticleRepository runs the contract against repositories created by
+// newRepository, which is called once per subtest.
+func ArticleRepository(t *testing.T, newRepository func(t *testing.T) domain.ArticleRepository) {
+	ctx := context.TODO()
+
+	t.Run("store-and-get", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 2)
+
+		assert.NotEqual(t, stored[0].ID, stored[1].ID)
+
+		res, err := repo.GetByID(ctx, stored[1].ID)
+		require.NoError(t, err)
+		assertSameArticle(t, stored[1], res)
+
+		res, err = repo.GetByTitle(ctx, stored[0].Title)
+		require.NoError(t, err)
+		assertSameArticle(t, stored[0], res)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		repo := newRepository(t)
+		seedArticles(t, repo, 1)
+
+		_, err := repo.GetByID(ctx, 4242)
+		assert.Equal(t, domain.ErrNotFound, err)
+
+		_, err = repo.GetByTitle(ctx, "missing")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("conflict-on-duplicate-title", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 1)
+
+		duplicate := newArticle(1)
+		duplicate.Title = stored[0].Title
+		assert.Equal(t, domain.ErrConflict, repo.Store(ctx, &duplicate))
+	})
+
+	t.Run("fetch-pages-in-created-order", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 5)
+
+		var seen []int64
+		page := domain.PageRequest{Num: 2}
+		for n := 0; n < 3; n++ {
+			list, info, err := repo.Fetch(ctx, domain.ArticleFilter{}, page)
+			require.NoError(t, err)
+
+			for _, a := range list {
+				seen = append(seen, a.ID)
+			}
+
+			assert.Equal(t, n > 0, info.HasPrev)
+			if n < 2 {
+				require.Len(t, list, 2)
+				require.True(t, info.HasNext)
+			} else {
+				require.Len(t, list, 1)
+				assert.False(t, info.HasNext)
+			}
+
+			last := domain.Sort{}.Position(list[len(list)-1])
+			page.After = &last
+		}
+
+		assert.Equal(t, articleIDs(stored), seen)
+	})
+
+	t.Run("fetch-pages-backwards", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 5)
+
+		before := domain.Sort{}.Position(stored[3])
+		list, info, err := repo.Fetch(ctx, domain.ArticleFilter{}, domain.PageRequest{Before: &before, Num: 2})
+		require.NoError(t, err)
+		assert.Equal(t, articleIDs(stored[1:3]), articleIDs(list))
+		assert.True(t, info.HasNext)
+		assert.True(t, info.HasPrev)
+
+		before = domain.Sort{}.Position(list[0])
+		list, info, err = repo.Fetch(ctx, domain.ArticleFilter{}, domain.PageRequest{Before: &before, Num: 2})
+		require.NoError(t, err)
+		assert.Equal(t, articleIDs(stored[:1]), articleIDs(list))
+		assert.False(t, info.HasPrev)
+	})
+
+	t.Run("fetch-does-not-skip-ties", func(t *testing.T) {
+		repo := newRepository(t)
+
+		var stored []domain.Article
+		for i := 0; i < 5; i++ {
+			a := newArticle(i)
+			a.CreatedAt = baseTime
+			require.NoError(t, repo.Store(ctx, &a))
+			stored = append(stored, a)
+		}
+
+		var seen []int64
+		page := domain.PageRequest{Num: 2}
+		for {
+			list, info, err := repo.Fetch(ctx, domain.ArticleFilter{}, page)
+			require.NoError(t, err)
+			seen = append(seen, articleIDs(list)...)
+
+			if !info.HasNext {
+				break
+			}
+			last := domain.Sort{}.Position(list[len(list)-1])
+			page.After = &last
+		}
+
+		assert.Equal(t, articleIDs(stored), seen)
+	})
+
+	t.Run("fetch-sorted", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 5)
+
+		// Titles run backwards from creation order, and two articles
+		// share their last update.
+		titles := []string{"Echo", "Delta", "Charlie", "Bravo", "Alpha"}
+		for i := range stored {
+			stored[i].Title = titles[i]
+			stored[i].UpdatedAt = baseTime.Add(time.Hour + time.Duration(i/2)*time.Minute)
+			require.NoError(t, repo.Update(ctx, &stored[i], stored[i].CreatedAt))
+		}
+
+		walk := func(by domain.Sort) []int64 {
+			var seen []int64
+			page := domain.PageRequest{Num: 2, Sort: by}
+			for {
+				list, info, err := repo.Fetch(ctx, domain.ArticleFilter{}, page)
+				require.NoError(t, err)
+				seen = append(seen, articleIDs(list)...)
+
+				if !info.HasNext {
+					return seen
+				}
+				last := by.Position(list[len(list)-1])
+				page.After = &last
+			}
+		}
+
+		ids := articleIDs(stored)
+		assert.Equal(t, []int64{ids[4], ids[3], ids[2], ids[1], ids[0]}, walk(domain.Sort{Field: domain.SortTitle}))
+		assert.Equal(t, ids, walk(domain.Sort{Field: domain.SortTitle, Desc: true}))
+		assert.Equal(t, ids, walk(domain.Sort{Field: domain.SortUpdatedAt}))
+		assert.Equal(t, []int64{ids[4], ids[3], ids[2], ids[1], ids[0]}, walk(domain.Sort{Field: domain.SortCreatedAt, Desc: true}))
+
+		by := domain.Sort{Field: domain.SortUpdatedAt, Desc: true}
+		before := by.Position(stored[1])
+		list, info, err := repo.Fetch(ctx, domain.ArticleFilter{}, domain.PageRequest{Before: &before, Num: 2, Sort: by})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{ids[3], ids[2]}, articleIDs(list))
+		assert.True(t, info.HasPrev)
+	})
+
+	t.Run("fetch-filtered", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 5)
+
+		stored[2].Title = "100% Done_right"
+		require.NoError(t, repo.Update(ctx, &stored[2], stored[2].UpdatedAt))
+
+		tests := map[string]struct {
+			filter   domain.ArticleFilter
+			expected []domain.Article
+		}{
+			"author":             {domain.ArticleFilter{AuthorID: 1}, []domain.Article{stored[0], stored[2], stored[4]}},
+			"created range":      {domain.ArticleFilter{CreatedFrom: stored[1].CreatedAt, CreatedTo: stored[3].CreatedAt}, stored[1:3]},
+			"updated from":       {domain.ArticleFilter{UpdatedFrom: stored[3].UpdatedAt}, stored[3:]},
+			"title prefix":       {domain.ArticleFilter{TitlePrefix: "article"}, []domain.Article{stored[0], stored[1], stored[3], stored[4]}},
+			"escaped wildcards":  {domain.ArticleFilter{TitlePrefix: "100% done_"}, stored[2:3]},
+			"wildcards are text": {domain.ArticleFilter{TitlePrefix: "%"}, nil},
+			"combined":           {domain.ArticleFilter{AuthorID: 2, TitlePrefix: "Article", CreatedTo: stored[3].CreatedAt}, stored[1:2]},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				list, _, err := repo.Fetch(ctx, tc.filter, domain.PageRequest{Num: 10})
+				require.NoError(t, err)
+				assert.Equal(t, articleIDs(tc.expected), articleIDs(list))
+			})
+		}
+	})
+
+	t.Run("fetch-by-author", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 5)
+
+		list, info, err := repo.FetchByAuthor(ctx, 2, domain.PageRequest{Num: 10})
+		require.NoError(t, err)
+		assert.False(t, info.HasNext)
+		assert.Equal(t, []int64{stored[1].ID, stored[3].ID}, articleIDs(list))
+
+		after := domain.Sort{}.Position(stored[1])
+		list, info, err = repo.FetchByAuthor(ctx, 2, domain.PageRequest{After: &after, Num: 1})
+		require.NoError(t, err)
+		assert.False(t, info.HasNext)
+		assert.True(t, info.HasPrev)
+		assert.Equal(t, []int64{stored[3].ID}, articleIDs(list))
+	})
+
+	t.Run("fetch-offset-and-count", func(t *testing.T) {
+		repo := newRepository(t)
+		ids := articleIDs(seedArticles(t, repo, 5))
+
+		page := func(n, perPage int64, by domain.Sort) []int64 {
+			list, err := repo.FetchOffset(ctx, domain.ArticleFilter{}, domain.OffsetRequest{Page: n, PerPage: perPage, Sort: by})
+			require.NoError(t, err)
+			return articleIDs(list)
+		}
+
+		assert.Equal(t, ids[:2], page(1, 2, domain.Sort{}))
+		assert.Equal(t, ids[2:4], page(2, 2, domain.Sort{}))
+		assert.Equal(t, ids[4:], page(3, 2, domain.Sort{}))
+		assert.Empty(t, page(4, 2, domain.Sort{}))
+		assert.Equal(t, []int64{ids[2], ids[1]}, page(2, 2, domain.Sort{Field: domain.SortCreatedAt, Desc: true}))
+
+		list, err := repo.FetchOffset(ctx, domain.ArticleFilter{AuthorID: 2}, domain.OffsetRequest{Page: 2, PerPage: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{ids[3]}, articleIDs(list))
+
+		total, err := repo.Count(ctx, domain.ArticleFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+
+		total, err = repo.Count(ctx, domain.ArticleFilter{AuthorID: 1, CreatedFrom: baseTime.Add(time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+
+		total, err = repo.Count(ctx, domain.ArticleFilter{TitlePrefix: "nothing"})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 1)[0]
+
+		changed := stored
+		changed.Title = "Changed title"
+		changed.Content = "Changed content"
+		changed.Author = domain.Author{ID: 7}
+		changed.UpdatedAt = stored.UpdatedAt.Add(time.Hour)
+		require.NoError(t, repo.Update(ctx, &changed, stored.UpdatedAt))
+
+		res, err := repo.GetByID(ctx, stored.ID)
+		require.NoError(t, err)
+		assertSameArticle(t, changed, res)
+		assert.True(t, stored.CreatedAt.Equal(res.CreatedAt))
+	})
+
+	t.Run("update-with-stale-version", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 1)[0]
+
+		first := stored
+		first.UpdatedAt = stored.UpdatedAt.Add(time.Hour)
+		require.NoError(t, repo.Update(ctx, &first, stored.UpdatedAt))
+
+		second := stored
+		second.Content = "Lost update"
+		second.UpdatedAt = stored.UpdatedAt.Add(2 * time.Hour)
+		assert.Equal(t, domain.ErrPreconditionFailed, repo.Update(ctx, &second, stored.UpdatedAt))
+	})
+
+	t.Run("update-missing", func(t *testing.T) {
+		repo := newRepository(t)
+
+		missing := newArticle(0)
+		missing.ID = 4242
+		assert.Equal(t, domain.ErrNotFound, repo.Update(ctx, &missing, missing.UpdatedAt))
+	})
+
+	t.Run("update-to-taken-title", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 2)
+
+		changed := stored[1]
+		changed.Title = stored[0].Title
+		changed.UpdatedAt = stored[1].UpdatedAt.Add(time.Hour)
+		assert.Equal(t, domain.ErrConflict, repo.Update(ctx, &changed, stored[1].UpdatedAt))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedArticles(t, repo, 2)
+
+		require.NoError(t, repo.Delete(ctx, stored[0].ID))
+
+		_, err := repo.GetByID(ctx, stored[0].ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+		assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, stored[0].ID))
+
+		_, err = repo.GetByID(ctx, stored[1].ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		repo := newRepository(t)
+		seedArticles(t, repo, 3)
+
+		gardening := newArticle(3)
+		gardening.Title = "Gardening with roses"
+		gardening.Content = "Roses need sun and roses need water"
+		require.NoError(t, repo.Store(ctx, &gardening))
+
+		results, info, err := repo.Search(ctx, "roses", nil, 10)
+		require.NoError(t, err)
+		assert.False(t, info.HasNext)
+		require.Len(t, results, 1)
+		assert.Equal(t, gardening.ID, results[0].Article.ID)
+		assert.Greater(t, results[0].Score, float64(0))
+		assert.Contains(t, results[0].Snippet, "<mark>")
+
+		_, _, err = repo.Search(ctx, " ", nil, 10)
+		assert.Equal(t, domain.ErrBadInput, err)
+	})
+
+	t.Run("search-pages", func(t *testing.T) {
+		repo := newRepository(t)
+
+		var stored []domain.Article
+		for i := 0; i < 3; i++ {
+			a := newArticle(i)
+			a.Content = "roses"
+			require.NoError(t, repo.Store(ctx, &a))
+			stored = append(stored, a)
+		}
+
+		results, info, err := repo.Search(ctx, "roses", nil, 3)
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+		assert.False(t, info.HasNext, "a full last page has nothing after it")
+
+		var seen []int64
+		var after *domain.SearchPosition
+		for {
+			results, info, err = repo.Search(ctx, "roses", after, 2)
+			require.NoError(t, err)
+			for _, r := range results {
+				seen = append(seen, r.Article.ID)
+			}
+
+			if !info.HasNext {
+				break
+			}
+			last := results[len(results)-1].Position()
+			after = &last
+		}
+
+		assert.Equal(t, articleIDs(stored), seen, "equal scores are ordered by id and not skipped")
+	})
+}
+
+func newArticle(i int) domain.Article {
+	created := baseTime.Add(time.Duration(i) * time.Minute)
+
+	return domain.Article{
+		Title:     fmt.Sprintf("Article %d", i),
+		Content:   fmt.Sprintf("Content of article number %d", i),
+		Author:    domain.Author{ID: int64(i%2 + 1)},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+// seedArticles stores n articles one minute apart, alternating between
+// authors 1 and 2, and returns them in created_at order.
+func seedArticles(t *testing.T, repo domain.ArticleRepository, n int) []domain.Article {
+	res := make([]domain.Article, 0, n)
+	for i := 0; i < n; i++ {
+		a := newArticle(i)
+		require.NoError(t, repo.Store(context.TODO(), &a))
+		require.NotZero(t, a.ID)
+		res = append(res, a)
+	}
+
+	return res
+}
+
+func articleIDs(list []domain.Article) []int64 {
+	ids := make([]int64, 0, len(list))
+	for _, a := range list {
+		ids = append(ids, a.ID)
+	}
+
+	return ids
+}
+
+func assertSameArticle(t *testing.T, expected, actual domain.Article) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Title, actual.Title)
+	assert.Equal(t, expected.Content, actual.Content)
+	assert.Equal(t, expected.Author.ID, actual.Author.ID)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at %s != %s", expected.CreatedAt, actual.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at %s != %s", expected.UpdatedAt, actual.UpdatedAt)
+}
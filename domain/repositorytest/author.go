@@ -0,0 +1,179 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// AuthorRepository runs the contract against repositories created by
+// newRepository, which is called once per subtest.
+func AuthorRepository(t *testing.T, newRepository func(t *testing.T) domain.AuthorRepository) {
+	ctx := context.TODO()
+
+	t.Run("store-and-get", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 2)
+
+		assert.NotEqual(t, stored[0].ID, stored[1].ID)
+
+		res, err := repo.GetByID(ctx, stored[1].ID)
+		require.NoError(t, err)
+		assertSameAuthor(t, stored[1], res)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		repo := newRepository(t)
+
+		_, err := repo.GetByID(ctx, 4242)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("get-by-ids", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 3)
+
+		res, err := repo.GetByIDs(ctx, []int64{stored[2].ID, 4242, stored[0].ID, stored[2].ID})
+		require.NoError(t, err)
+		require.Len(t, res, 2, "missing ids are left out")
+		assertSameAuthor(t, stored[0], res[stored[0].ID])
+		assertSameAuthor(t, stored[2], res[stored[2].ID])
+
+		res, err = repo.GetByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("fetch-pages-in-created-order", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 3)
+
+		first, info, err := repo.Fetch(ctx, domain.PageRequest{Num: 2})
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		assert.True(t, info.HasNext)
+		assert.False(t, info.HasPrev)
+
+		after := first[1].Position()
+		second, info, err := repo.Fetch(ctx, domain.PageRequest{After: &after, Num: 2})
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.False(t, info.HasNext)
+		assert.True(t, info.HasPrev)
+
+		assert.Equal(t, authorIDs(stored), authorIDs(append(first, second...)))
+	})
+
+	t.Run("fetch-pages-backwards", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 5)
+
+		before := stored[3].Position()
+		list, info, err := repo.Fetch(ctx, domain.PageRequest{Before: &before, Num: 2})
+		require.NoError(t, err)
+		assert.Equal(t, authorIDs(stored[1:3]), authorIDs(list))
+		assert.True(t, info.HasNext)
+		assert.True(t, info.HasPrev)
+
+		before = list[0].Position()
+		list, info, err = repo.Fetch(ctx, domain.PageRequest{Before: &before, Num: 2})
+		require.NoError(t, err)
+		assert.Equal(t, authorIDs(stored[:1]), authorIDs(list))
+		assert.False(t, info.HasPrev)
+	})
+
+	t.Run("fetch-does-not-skip-ties", func(t *testing.T) {
+		repo := newRepository(t)
+
+		var stored []domain.Author
+		for i := 0; i < 5; i++ {
+			au := domain.Author{Name: fmt.Sprintf("Author %d", i), CreatedAt: baseTime, UpdatedAt: baseTime}
+			require.NoError(t, repo.Store(ctx, &au))
+			stored = append(stored, au)
+		}
+
+		var seen []int64
+		page := domain.PageRequest{Num: 2}
+		for {
+			list, info, err := repo.Fetch(ctx, page)
+			require.NoError(t, err)
+			seen = append(seen, authorIDs(list)...)
+
+			if !info.HasNext {
+				break
+			}
+			last := list[len(list)-1].Position()
+			page.After = &last
+		}
+
+		assert.Equal(t, authorIDs(stored), seen)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 1)[0]
+
+		changed := stored
+		changed.Name = "Richard Bachman"
+		changed.UpdatedAt = stored.UpdatedAt.Add(time.Hour)
+		require.NoError(t, repo.Update(ctx, &changed))
+
+		res, err := repo.GetByID(ctx, stored.ID)
+		require.NoError(t, err)
+		assertSameAuthor(t, changed, res)
+
+		missing := changed
+		missing.ID = 4242
+		assert.Equal(t, domain.ErrNotFound, repo.Update(ctx, &missing))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepository(t)
+		stored := seedAuthors(t, repo, 1)[0]
+
+		require.NoError(t, repo.Delete(ctx, stored.ID))
+
+		_, err := repo.GetByID(ctx, stored.ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+		assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, stored.ID))
+	})
+}
+
+func seedAuthors(t *testing.T, repo domain.AuthorRepository, n int) []domain.Author {
+	res := make([]domain.Author, 0, n)
+	for i := 0; i < n; i++ {
+		created := baseTime.Add(time.Duration(i) * time.Minute)
+		au := domain.Author{
+			Name:      fmt.Sprintf("Author %d", i),
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+		require.NoError(t, repo.Store(context.TODO(), &au))
+		require.NotZero(t, au.ID)
+		res = append(res, au)
+	}
+
+	return res
+}
+
+func assertSameAuthor(t *testing.T, expected, actual domain.Author) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.True(t, expected.CreatedAt.Equal(actual.CreatedAt), "created_at %s != %s", expected.CreatedAt, actual.CreatedAt)
+	assert.True(t, expected.UpdatedAt.Equal(actual.UpdatedAt), "updated_at %s != %s", expected.UpdatedAt, actual.UpdatedAt)
+}
+
+func authorIDs(list []domain.Author) []int64 {
+	ids := make([]int64, 0, len(list))
+	for _, au := range list {
+		ids = append(ids, au.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"github.com/labstack/echo"
+	"net/http"
+)
+
+type Handler struct {
+	Health *Health
+}
+
+// NewHandler registers /healthz, which only says the process is serving, and
+// /readyz, which runs the checkers and answers 503 when any fails.
+func NewHandler(e *echo.Echo, h *Health) {
+	handler := &Handler{
+		Health: h,
+	}
+
+	e.GET("/healthz", handler.Live)
+	e.GET("/readyz", handler.Ready)
+}
+
+func (hh *Handler) Live(ec echo.Context) error {
+	return ec.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+func (hh *Handler) Ready(ec echo.Context) error {
+	report, ok := hh.Health.Ready(ec.Request().Context())
+	if !ok {
+		return ec.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return ec.JSON(http.StatusOK, report)
+}
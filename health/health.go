@@ -0,0 +1,141 @@
+// Package health reports whether the service is alive and ready to take
+// traffic. Subsystems register a Checker for each dependency they need;
+// readiness fails when any of them fails or once shutdown has started.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Checker reports whether one dependency is usable. The details, when not
+// nil, are included in the readiness report whether the check passed or not.
+type Checker interface {
+	Check(ctx context.Context) (details interface{}, err error)
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) (interface{}, error)
+
+func (f CheckerFunc) Check(ctx context.Context) (interface{}, error) {
+	return f(ctx)
+}
+
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckResult is the outcome of one checker.
+type CheckResult struct {
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Duration string      `json:"duration"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// Report is the outcome of a readiness check.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Health holds the registered checkers. The zero value is not usable; call
+// New.
+type Health struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers map[string]Checker
+
+	shuttingDown int32
+}
+
+// New returns a Health that gives every checker timeout to answer.
+func New(timeout time.Duration) *Health {
+	return &Health{
+		timeout:  timeout,
+		checkers: map[string]Checker{},
+	}
+}
+
+// Register adds a checker under name, replacing any previous one.
+func (h *Health) Register(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers[name] = c
+}
+
+// ShuttingDown makes readiness fail from now on, so load balancers stop
+// sending traffic while in-flight requests drain.
+func (h *Health) ShuttingDown() {
+	atomic.StoreInt32(&h.shuttingDown, 1)
+}
+
+func (h *Health) isShuttingDown() bool {
+	return atomic.LoadInt32(&h.shuttingDown) == 1
+}
+
+// Ready runs every checker concurrently and reports whether all passed.
+func (h *Health) Ready(ctx context.Context) (Report, bool) {
+	if h.isShuttingDown() {
+		return Report{Status: StatusShuttingDown}, false
+	}
+
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checkers))
+	for name := range h.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checkers := make([]Checker, len(names))
+	for i, name := range names {
+		checkers[i] = h.checkers[name]
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func(i int, c Checker) {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+
+	return report, report.Status == StatusOK
+}
+
+func (h *Health) run(ctx context.Context, c Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.Check(ctx)
+	res := CheckResult{
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+		Details:  details,
+	}
+	if err != nil {
+		res.Status = StatusFailing
+		res.Error = err.Error()
+	}
+
+	return res
+}
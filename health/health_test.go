@@ -0,0 +1,95 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/health"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) (interface{}, error) {
+	return map[string]int{"answer": 42}, nil
+}
+
+func failing(context.Context) (interface{}, error) {
+	return nil, errors.New("unreachable")
+}
+
+func slow(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestReady(t *testing.T) {
+	h := health.New(50 * time.Millisecond)
+
+	report, ready := h.Ready(context.TODO())
+	assert.True(t, ready, "no checkers means ready")
+	assert.Equal(t, health.StatusOK, report.Status)
+
+	h.Register("ok", health.CheckerFunc(ok))
+	report, ready = h.Ready(context.TODO())
+	assert.True(t, ready)
+	assert.Equal(t, health.StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, map[string]int{"answer": 42}, report.Checks["ok"].Details)
+
+	h.Register("failing", health.CheckerFunc(failing))
+	h.Register("slow", health.CheckerFunc(slow))
+	report, ready = h.Ready(context.TODO())
+	assert.False(t, ready)
+	assert.Equal(t, health.StatusFailing, report.Status)
+	assert.Equal(t, "unreachable", report.Checks["failing"].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+	assert.Equal(t, health.StatusOK, report.Checks["ok"].Status)
+}
+
+func TestReadyFailsWhileShuttingDown(t *testing.T) {
+	h := health.New(time.Second)
+	h.Register("ok", health.CheckerFunc(ok))
+	h.ShuttingDown()
+
+	report, ready := h.Ready(context.TODO())
+	assert.False(t, ready)
+	assert.Equal(t, health.StatusShuttingDown, report.Status)
+}
+
+func TestDB(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	details, err := health.DB(db).Check(context.TODO())
+	require.NoError(t, err)
+	assert.IsType(t, health.PoolStats{}, details)
+
+	db.Close()
+	_, err = health.DB(db).Check(context.TODO())
+	assert.Error(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	e := echo.New()
+	h := health.New(time.Second)
+	health.NewHandler(e, h)
+	h.Register("failing", health.CheckerFunc(failing))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusFailing, report.Status)
+	assert.Equal(t, "unreachable", report.Checks["failing"].Error)
+}
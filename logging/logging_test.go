@@ -0,0 +1,42 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"testing"
+	"time"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), logging.FromContext(context.TODO()))
+
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo).With("request_id", "abc")
+	ctx := logging.WithRequestID(logging.WithContext(context.TODO(), logger), "abc")
+
+	assert.Equal(t, "abc", logging.RequestID(ctx))
+	assert.Empty(t, logging.RequestID(context.TODO()))
+
+	logging.FromContext(ctx).Debug("hidden")
+	logging.FromContext(ctx).Info("took", "duration", 1500*time.Millisecond)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "took", line["msg"])
+	assert.Equal(t, "abc", line["request_id"])
+	assert.Equal(t, "1.5s", line["duration"])
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := logging.ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = logging.ParseLevel("loud")
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"time"
+)
+
+// articleUseCase times every call to the wrapped domain.ArticleUseCase.
+type articleUseCase struct {
+	next    domain.ArticleUseCase
+	metrics *Metrics
+}
+
+// ArticleUseCase wraps next so each method call is observed in
+// usecase_duration_seconds.
+func (m *Metrics) ArticleUseCase(next domain.ArticleUseCase) domain.ArticleUseCase {
+	return &articleUseCase{next: next, metrics: m}
+}
+
+// observe is deferred with a pointer to the named error result, so it sees
+// the error actually returned.
+func (a *articleUseCase) observe(method string, start time.Time, err *error) {
+	a.metrics.observeUseCase("article", method, start, *err)
+}
+
+func (a *articleUseCase) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	defer a.observe("Fetch", time.Now(), &err)
+	return a.next.Fetch(ctx, filter, page)
+}
+
+func (a *articleUseCase) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	defer a.observe("FetchByAuthor", time.Now(), &err)
+	return a.next.FetchByAuthor(ctx, authorID, page)
+}
+
+func (a *articleUseCase) FetchPage(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) (res []domain.Article, total int64, err error) {
+	defer a.observe("FetchPage", time.Now(), &err)
+	return a.next.FetchPage(ctx, filter, page)
+}
+
+func (a *articleUseCase) GetByID(ctx context.Context, id int64) (res domain.Article, err error) {
+	defer a.observe("GetByID", time.Now(), &err)
+	return a.next.GetByID(ctx, id)
+}
+
+func (a *articleUseCase) Update(ctx context.Context, ar *domain.Article, version time.Time) (err error) {
+	defer a.observe("Update", time.Now(), &err)
+	return a.next.Update(ctx, ar, version)
+}
+
+func (a *articleUseCase) GetByTitle(ctx context.Context, title string) (res domain.Article, err error) {
+	defer a.observe("GetByTitle", time.Now(), &err)
+	return a.next.GetByTitle(ctx, title)
+}
+
+func (a *articleUseCase) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) (res []domain.ArticleSearchResult, info domain.PageInfo, err error) {
+	defer a.observe("Search", time.Now(), &err)
+	return a.next.Search(ctx, query, after, num)
+}
+
+func (a *articleUseCase) Store(ctx context.Context, ar *domain.Article) (err error) {
+	defer a.observe("Store", time.Now(), &err)
+	return a.next.Store(ctx, ar)
+}
+
+func (a *articleUseCase) Delete(ctx context.Context, id int64) (err error) {
+	defer a.observe("Delete", time.Now(), &err)
+	return a.next.Delete(ctx, id)
+}
+
+// articleRepository times every call to the wrapped domain.ArticleRepository.
+type articleRepository struct {
+	next    domain.ArticleRepository
+	metrics *Metrics
+}
+
+// ArticleRepository wraps next so each method call is observed in
+// repository_duration_seconds.
+func (m *Metrics) ArticleRepository(next domain.ArticleRepository) domain.ArticleRepository {
+	return &articleRepository{next: next, metrics: m}
+}
+
+func (a *articleRepository) observe(method string, start time.Time, err *error) {
+	a.metrics.observeRepository("article", method, start, *err)
+}
+
+func (a *articleRepository) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	defer a.observe("Fetch", time.Now(), &err)
+	return a.next.Fetch(ctx, filter, page)
+}
+
+func (a *articleRepository) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	defer a.observe("FetchByAuthor", time.Now(), &err)
+	return a.next.FetchByAuthor(ctx, authorID, page)
+}
+
+func (a *articleRepository) FetchOffset(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) (res []domain.Article, err error) {
+	defer a.observe("FetchOffset", time.Now(), &err)
+	return a.next.FetchOffset(ctx, filter, page)
+}
+
+func (a *articleRepository) Count(ctx context.Context, filter domain.ArticleFilter) (total int64, err error) {
+	defer a.observe("Count", time.Now(), &err)
+	return a.next.Count(ctx, filter)
+}
+
+func (a *articleRepository) GetByID(ctx context.Context, id int64) (res domain.Article, err error) {
+	defer a.observe("GetByID", time.Now(), &err)
+	return a.next.GetByID(ctx, id)
+}
+
+func (a *articleRepository) GetByTitle(ctx context.Context, title string) (res domain.Article, err error) {
+	defer a.observe("GetByTitle", time.Now(), &err)
+	return a.next.GetByTitle(ctx, title)
+}
+
+func (a *articleRepository) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) (res []domain.ArticleSearchResult, info domain.PageInfo, err error) {
+	defer a.observe("Search", time.Now(), &err)
+	return a.next.Search(ctx, query, after, num)
+}
+
+func (a *articleRepository) Update(ctx context.Context, ar *domain.Article, version time.Time) (err error) {
+	defer a.observe("Update", time.Now(), &err)
+	return a.next.Update(ctx, ar, version)
+}
+
+func (a *articleRepository) Store(ctx context.Context, ar *domain.Article) (err error) {
+	defer a.observe("Store", time.Now(), &err)
+	return a.next.Store(ctx, ar)
+}
+
+func (a *articleRepository) Delete(ctx context.Context, id int64) (err error) {
+	defer a.observe("Delete", time.Now(), &err)
+	return a.next.Delete(ctx, id)
+}
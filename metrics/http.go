@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"strconv"
+	"time"
+)
+
+// Middleware records every request under the route pattern it matched, such
+// as /articles/:id, so ids do not blow up the label set.
+func (m *Metrics) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := c.Response().Status
+		if err != nil {
+			status = problem.StatusCode(err)
+		}
+
+		labels := []string{c.Request().Method, route, strconv.Itoa(status)}
+		m.httpRequests.WithLabelValues(labels...).Inc()
+		m.httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
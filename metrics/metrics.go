@@ -0,0 +1,87 @@
+// Package metrics exposes Prometheus metrics for the service: HTTP traffic
+// per echo route, call timings for the use case and repository layers, and
+// the database connection pool.
+package metrics
+
+import (
+	"database/sql"
+	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"time"
+)
+
+// Metrics owns a registry and the collectors recorded into it.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	useCaseCalls   *prometheus.HistogramVec
+	repositoryCall *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests handled, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time spent handling HTTP requests, by method, route and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		useCaseCalls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "usecase_duration_seconds",
+			Help:    "Time spent in use case methods, by use case, method and outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"usecase", "method", "outcome"}),
+		repositoryCall: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_duration_seconds",
+			Help:    "Time spent in repository methods, by repository, method and outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"repository", "method", "outcome"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.useCaseCalls,
+		m.repositoryCall,
+	)
+
+	return m
+}
+
+// RegisterDB exports the sql.DBStats of db as go_sql_* metrics labelled
+// with name.
+func (m *Metrics) RegisterDB(name string, db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
+
+// outcome keeps the label set small: errors are not told apart.
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "ok"
+}
+
+func (m *Metrics) observeUseCase(usecase, method string, start time.Time, err error) {
+	m.useCaseCalls.WithLabelValues(usecase, method, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) observeRepository(repository, method string, start time.Time, err error) {
+	m.repositoryCall.WithLabelValues(repository, method, outcome(err)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,113 @@
+package metrics_test
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/angelRaynov/clean-architecture/metrics"
+	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := metrics.New()
+	e := echo.New()
+	e.Use(m.Middleware)
+	e.GET("/metrics", m.Handler())
+	e.GET("/articles/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return echo.NewHTTPError(http.StatusBadRequest)
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	for _, path := range []string{"/articles/1", "/articles/2", "/articles/0", "/missing"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(echo.GET, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/articles/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/articles/:id",status="400"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/articles/:id",status="200"} 2`)
+	assert.Contains(t, body, `status="404"`)
+	assert.NotContains(t, body, "/articles/1")
+}
+
+func TestArticleUseCase(t *testing.T) {
+	m := metrics.New()
+	mockUCase := new(mocks.ArticleUseCase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(domain.Article{ID: 1}, nil).Once()
+	mockUCase.On("GetByID", mock.Anything, int64(2)).Return(domain.Article{}, domain.ErrNotFound).Once()
+	mockUCase.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	u := m.ArticleUseCase(mockUCase)
+
+	a, err := u.GetByID(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), a.ID)
+
+	_, err = u.GetByID(context.TODO(), 2)
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	require.NoError(t, u.Update(context.TODO(), &domain.Article{ID: 1}, time.Now()))
+
+	mockUCase.AssertExpectations(t)
+	assertSeries(t, m, `usecase_duration_seconds_count{method="GetByID",outcome="ok",usecase="article"} 1`)
+	assertSeries(t, m, `usecase_duration_seconds_count{method="GetByID",outcome="error",usecase="article"} 1`)
+	assertSeries(t, m, `usecase_duration_seconds_count{method="Update",outcome="ok",usecase="article"} 1`)
+}
+
+func TestArticleRepository(t *testing.T) {
+	m := metrics.New()
+	mockRepo := new(mocks.ArticleRepository)
+	mockRepo.On("Fetch", mock.Anything, domain.ArticleFilter{}, domain.PageRequest{Num: 10}).Return([]domain.Article{{ID: 1}}, domain.PageInfo{HasNext: true}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, int64(3)).Return(domain.ErrNotFound).Once()
+
+	r := m.ArticleRepository(mockRepo)
+
+	list, info, err := r.Fetch(context.TODO(), domain.ArticleFilter{}, domain.PageRequest{Num: 10})
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.True(t, info.HasNext)
+
+	assert.Equal(t, domain.ErrNotFound, r.Delete(context.TODO(), 3))
+
+	mockRepo.AssertExpectations(t)
+	assertSeries(t, m, `repository_duration_seconds_count{method="Fetch",outcome="ok",repository="article"} 1`)
+	assertSeries(t, m, `repository_duration_seconds_count{method="Delete",outcome="error",repository="article"} 1`)
+}
+
+func TestRegisterDB(t *testing.T) {
+	m := metrics.New()
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m.RegisterDB("article", db)
+
+	count, err := testutil.GatherAndCount(m.Registry, "go_sql_open_connections", "go_sql_max_open_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func assertSeries(t *testing.T, m *metrics.Metrics, series string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	ec := echo.New().NewContext(httptest.NewRequest(echo.GET, "/metrics", nil), rec)
+	require.NoError(t, m.Handler()(ec))
+	assert.True(t, strings.Contains(rec.Body.String(), series), "missing %s", series)
+}
@@ -0,0 +1,143 @@
+// Package migration versions the database schema. The SQL for every dialect is
+// embedded in the binary under sql/<dialect>/ as pairs of
+// <version>_<name>.up.sql and <version>_<name>.down.sql files, and applied
+// versions are recorded in the schema_migrations table.
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql
+var files embed.FS
+
+// Migration is one schema change and the statements that revert it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load returns the embedded migrations of d ordered by version.
+func Load(d dialect.Dialect) ([]Migration, error) {
+	return load(files, path.Join("sql", string(d)))
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations in %s: %w", dir, err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		res = append(res, *m)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+
+	return res, nil
+}
+
+// parseFileName splits 0001_create_author.up.sql into its version, name and
+// direction.
+func parseFileName(fileName string) (version int64, name string, direction string, err error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("migration %q must end in .up.sql or .down.sql", fileName)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", "", fmt.Errorf("migration %q must be named <version>_<name>", fileName)
+	}
+
+	version, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %q has an invalid version", fileName)
+	}
+
+	return version, parts[1], direction, nil
+}
+
+// statements splits a migration file on the semicolons ending its lines, so
+// drivers that only run one statement per Exec can apply it. Lines starting
+// with -- are comments.
+func statements(body string) []string {
+	var (
+		res []string
+		cur strings.Builder
+	)
+
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		cur.WriteString(line)
+		cur.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			res = append(res, strings.TrimSuffix(strings.TrimSpace(cur.String()), ";"))
+			cur.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(cur.String()); rest != "" {
+		res = append(res, rest)
+	}
+
+	return res
+}
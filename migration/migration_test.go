@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	for _, d := range []dialect.Dialect{dialect.MySQL, dialect.PostgreSQL, dialect.SQLite} {
+		t.Run(string(d), func(t *testing.T) {
+			migrations, err := Load(d)
+			require.NoError(t, err)
+			require.NotEmpty(t, migrations)
+
+			for i, m := range migrations {
+				assert.Equal(t, int64(i+1), m.Version)
+				assert.NotEmpty(t, statements(m.Up))
+				assert.NotEmpty(t, statements(m.Down))
+			}
+		})
+	}
+}
+
+func TestLoadRejectsBrokenDirectories(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing-down": {
+			"m/0001_create.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+		"bad-version": {
+			"m/first_create.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"m/first_create.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+		"bad-suffix": {
+			"m/0001_create.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+		"name-mismatch": {
+			"m/0001_create.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+			"m/0001_drop.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(fsys, "m")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStatements(t *testing.T) {
+	body := `-- the author table
+CREATE TABLE author (
+    id INT
+);
+
+CREATE INDEX author_id_idx ON author (id);
+DROP INDEX other`
+
+	assert.Equal(t, []string{
+		"CREATE TABLE author (\n    id INT\n)",
+		"CREATE INDEX author_id_idx ON author (id)",
+		"DROP INDEX other",
+	}, statements(body))
+}
@@ -0,0 +1,31 @@
+//go:build cgo
+
+// Package migrationtest opens migrated databases for repository tests.
+package migrationtest
+
+import (
+	"context"
+	"database/sql"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/migration"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+// OpenSQLite returns a SQLite database in a temporary directory with every
+// migration applied. It is closed when the test ends.
+func OpenSQLite(t *testing.T) *sql.DB {
+	dsn := dialect.SQLite.DSN("", "", "", "", filepath.Join(t.TempDir(), "test.db"))
+	db, err := sql.Open(dialect.SQLite.DriverName(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migration.NewMigrator(db, dialect.SQLite)
+	require.NoError(t, err)
+	_, err = m.Up(context.TODO())
+	require.NoError(t, err)
+
+	return db
+}
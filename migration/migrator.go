@@ -0,0 +1,181 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"time"
+)
+
+// Status tells whether a migration has been applied and when.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts the embedded migrations of one dialect.
+type Migrator struct {
+	DB         *sql.DB
+	dialect    dialect.Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, d dialect.Dialect) (*Migrator, error) {
+	migrations, err := Load(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		dialect:    d,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied. It stops at the first failure.
+func (m *Migrator) Up(ctx context.Context) (res []Migration, err error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		err = m.run(ctx, mig, mig.Up, func(tx *sql.Tx) error {
+			query := m.dialect.Rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`)
+			_, err := tx.ExecContext(ctx, query, m.dialect.Args(mig.Version, mig.Name, time.Now().UTC().Truncate(time.Second))...)
+			return err
+		})
+		if err != nil {
+			return res, err
+		}
+
+		res = append(res, mig)
+	}
+
+	return res, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first, and returns
+// the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (res []Migration, err error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && len(res) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+
+		err = m.run(ctx, mig, mig.Down, func(tx *sql.Tx) error {
+			query := m.dialect.Rebind(`DELETE FROM schema_migrations WHERE version = ?`)
+			_, err := tx.ExecContext(ctx, query, mig.Version)
+			return err
+		})
+		if err != nil {
+			return res, err
+		}
+
+		res = append(res, mig)
+	}
+
+	return res, nil
+}
+
+// Status lists every known migration in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		appliedAt, ok := applied[mig.Version]
+		res = append(res, Status{
+			Migration: mig,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// run executes the statements of one migration and records the change in a
+// single transaction. MySQL commits DDL implicitly, so there a failed
+// migration may be left half applied.
+func (m *Migrator) run(ctx context.Context, mig Migration, body string, record func(tx *sql.Tx) error) (err error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, stmt := range statements(body) {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	if err = record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applied creates schema_migrations when missing and returns the applied
+// versions with the time they were applied.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if _, err := m.DB.ExecContext(ctx, m.schemaMigrationsTable()); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		res[version] = appliedAt
+	}
+
+	return res, rows.Err()
+}
+
+func (m *Migrator) schemaMigrationsTable() string {
+	appliedAt := "DATETIME"
+	if m.dialect == dialect.PostgreSQL {
+		appliedAt = "TIMESTAMPTZ"
+	}
+
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	applied_at ` + appliedAt + ` NOT NULL
+)`
+}
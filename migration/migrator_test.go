@@ -0,0 +1,70 @@
+//go:build cgo
+
+package migration_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/migration"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrator_SQLite(t *testing.T) {
+	ctx := context.TODO()
+	dsn := dialect.SQLite.DSN("", "", "", "", filepath.Join(t.TempDir(), "migrate.db"))
+	db, err := sql.Open(dialect.SQLite.DriverName(), dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migration.NewMigrator(db, dialect.SQLite)
+	require.NoError(t, err)
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, status)
+	for _, s := range status {
+		assert.False(t, s.Applied)
+	}
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(status))
+
+	_, err = db.Exec(`INSERT INTO article (title, content) VALUES ('a', 'b')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO article (title, content) VALUES ('a', 'c')`)
+	assert.Error(t, err, "titles must be unique")
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "a second up is a no-op")
+
+	status, err = m.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range status {
+		assert.True(t, s.Applied)
+		assert.False(t, s.AppliedAt.IsZero())
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, status[len(status)-1].Version, reverted[0].Version)
+
+	status, err = m.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, status[len(status)-1].Applied)
+	assert.True(t, status[0].Applied)
+
+	reverted, err = m.Down(ctx, len(status))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(status)-1)
+
+	_, err = db.Exec(`SELECT 1 FROM article`)
+	assert.Error(t, err, "down drops the tables")
+}
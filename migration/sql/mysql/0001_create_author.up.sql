@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS author (
+    id         INT(11) UNSIGNED NOT NULL AUTO_INCREMENT,
+    name       VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT NULL,
+    updated_at DATETIME DEFAULT NULL,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
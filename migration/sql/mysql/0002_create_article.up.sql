@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS article (
+    id         INT(11) NOT NULL AUTO_INCREMENT,
+    title      VARCHAR(45) NOT NULL,
+    content    LONGTEXT NOT NULL,
+    author_id  INT(11) DEFAULT 0,
+    updated_at DATETIME DEFAULT NULL,
+    created_at DATETIME DEFAULT NULL,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
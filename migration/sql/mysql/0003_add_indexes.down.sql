@@ -0,0 +1,5 @@
+DROP INDEX author_created_at_idx ON author;
+DROP INDEX article_title_content_ft ON article;
+DROP INDEX article_author_id_created_at_idx ON article;
+DROP INDEX article_created_at_idx ON article;
+DROP INDEX article_title_idx ON article;
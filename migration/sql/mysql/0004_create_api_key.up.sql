@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id           INT(11) UNSIGNED NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(200) NOT NULL DEFAULT '',
+    prefix       VARCHAR(32) NOT NULL,
+    hash         CHAR(64) NOT NULL,
+    scopes       VARCHAR(200) NOT NULL DEFAULT '',
+    author_id    INT(11) NOT NULL DEFAULT 0,
+    created_at   DATETIME DEFAULT NULL,
+    last_used_at DATETIME DEFAULT NULL,
+    revoked_at   DATETIME DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY api_key_prefix_idx (prefix)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
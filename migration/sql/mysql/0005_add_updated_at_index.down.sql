@@ -0,0 +1 @@
+DROP INDEX article_updated_at_idx ON article;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS author (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS article (
+    id         BIGSERIAL PRIMARY KEY,
+    title      VARCHAR(45) NOT NULL,
+    content    TEXT NOT NULL,
+    author_id  BIGINT DEFAULT 0,
+    updated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
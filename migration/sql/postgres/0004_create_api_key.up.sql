@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(200) NOT NULL DEFAULT '',
+    prefix       VARCHAR(32) NOT NULL,
+    hash         CHAR(64) NOT NULL,
+    scopes       VARCHAR(200) NOT NULL DEFAULT '',
+    author_id    BIGINT NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX api_key_prefix_idx ON api_key (prefix);
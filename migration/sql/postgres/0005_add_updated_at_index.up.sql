@@ -0,0 +1 @@
+CREATE INDEX article_updated_at_idx ON article (updated_at);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS author (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(200) NOT NULL DEFAULT '',
+    created_at DATETIME,
+    updated_at DATETIME
+);
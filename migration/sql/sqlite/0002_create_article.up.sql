@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS article (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    title      VARCHAR(45) NOT NULL,
+    content    TEXT NOT NULL,
+    author_id  INTEGER DEFAULT 0,
+    updated_at DATETIME,
+    created_at DATETIME
+);
@@ -0,0 +1,4 @@
+DROP INDEX author_created_at_idx;
+DROP INDEX article_author_id_created_at_idx;
+DROP INDEX article_created_at_idx;
+DROP INDEX article_title_idx;
@@ -0,0 +1,4 @@
+CREATE UNIQUE INDEX article_title_idx ON article (title);
+CREATE INDEX article_created_at_idx ON article (created_at);
+CREATE INDEX article_author_id_created_at_idx ON article (author_id, created_at);
+CREATE INDEX author_created_at_idx ON author (created_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         VARCHAR(200) NOT NULL DEFAULT '',
+    prefix       VARCHAR(32) NOT NULL,
+    hash         CHAR(64) NOT NULL,
+    scopes       VARCHAR(200) NOT NULL DEFAULT '',
+    author_id    INTEGER NOT NULL DEFAULT 0,
+    created_at   DATETIME,
+    last_used_at DATETIME,
+    revoked_at   DATETIME
+);
+CREATE UNIQUE INDEX api_key_prefix_idx ON api_key (prefix);
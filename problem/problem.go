@@ -0,0 +1,203 @@
+// Package problem turns the errors returned by echo handlers into RFC 7807
+// problem details. Handlers return errors as they are; Handler, installed as
+// the echo HTTPErrorHandler, decides what the client gets to see.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/labstack/echo"
+	validator "gopkg.in/go-playground/validator.v9"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// Problem is the RFC 7807 body, extended with the domain error code, the
+// invalid fields of a validation error and the request ID.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      domain.ErrorCode    `json:"code"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+var statusByCode = map[domain.ErrorCode]int{
+	domain.CodeNotFound:             http.StatusNotFound,
+	domain.CodeConflict:             http.StatusConflict,
+	domain.CodeBadInput:             http.StatusBadRequest,
+	domain.CodeValidation:           http.StatusUnprocessableEntity,
+	domain.CodePreconditionFailed:   http.StatusPreconditionFailed,
+	domain.CodePreconditionRequired: http.StatusPreconditionRequired,
+	domain.CodeUnauthorized:         http.StatusUnauthorized,
+	domain.CodeForbidden:            http.StatusForbidden,
+	domain.CodeRateLimited:          http.StatusTooManyRequests,
+	domain.CodeInternal:             http.StatusInternalServerError,
+}
+
+var codeByStatus = map[int]domain.ErrorCode{
+	http.StatusNotFound:             domain.CodeNotFound,
+	http.StatusConflict:             domain.CodeConflict,
+	http.StatusUnprocessableEntity:  domain.CodeValidation,
+	http.StatusPreconditionFailed:   domain.CodePreconditionFailed,
+	http.StatusPreconditionRequired: domain.CodePreconditionRequired,
+	http.StatusUnauthorized:         domain.CodeUnauthorized,
+	http.StatusForbidden:            domain.CodeForbidden,
+	http.StatusTooManyRequests:      domain.CodeRateLimited,
+}
+
+// StatusCode returns the HTTP status the response to err will have.
+func StatusCode(err error) int {
+	return From(err).Status
+}
+
+// From describes err as a problem. Only domain errors, validation errors and
+// echo's own HTTP errors keep their message; anything else is an internal
+// error whose details stay out of the response.
+func From(err error) Problem {
+	var (
+		de  *domain.Error
+		he  *echo.HTTPError
+		ves validator.ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &ves):
+		de = domain.NewValidationError(fieldErrors(ves)...)
+	case errors.As(err, &de):
+	case errors.As(err, &he):
+		return fromHTTPError(he)
+	default:
+		de = domain.ErrInternalServerError
+	}
+
+	status, ok := statusByCode[de.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := de.Message
+	if status == http.StatusInternalServerError {
+		detail = domain.ErrInternalServerError.Message
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   de.Code,
+		Errors: de.Fields,
+	}
+}
+
+func fromHTTPError(he *echo.HTTPError) Problem {
+	code, ok := codeByStatus[he.Code]
+	if !ok {
+		code = domain.CodeBadInput
+		if he.Code >= http.StatusInternalServerError {
+			code = domain.CodeInternal
+		}
+	}
+
+	detail := fmt.Sprint(he.Message)
+	if he.Code >= http.StatusInternalServerError {
+		detail = domain.ErrInternalServerError.Message
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(he.Code),
+		Status: he.Code,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// NewValidator returns a validator that names fields by their JSON key, which
+// is what clients sent. Fields without a json tag keep their Go name.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// fieldErrors lists the invalid fields under the names the validator gave
+// them; see NewValidator.
+func fieldErrors(ves validator.ValidationErrors) []domain.FieldError {
+	res := make([]domain.FieldError, 0, len(ves))
+	for _, fe := range ves {
+		res = append(res, domain.FieldError{
+			Field:   fe.Field(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return res
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		return "must be at most " + fe.Param() + " characters long"
+	case "min":
+		return "must be at least " + fe.Param() + " characters long"
+	default:
+		return "fails the " + fe.Tag() + " rule"
+	}
+}
+
+// Handler is an echo.HTTPErrorHandler writing err as problem+json. Internal
+// errors are logged with their cause through the request logger.
+func Handler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	req := c.Request()
+	p := From(err)
+	p.Instance = req.URL.Path
+	p.RequestID = logging.RequestID(req.Context())
+
+	if p.Status >= http.StatusInternalServerError {
+		logging.FromContext(req.Context()).Error("request failed", "err", err)
+	}
+
+	if p.Status == http.StatusUnauthorized && c.Response().Header().Get(echo.HeaderWWWAuthenticate) == "" {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	}
+
+	if req.Method == http.MethodHead {
+		_ = c.NoContent(p.Status)
+		return
+	}
+
+	body, jsonErr := json.Marshal(p)
+	if jsonErr != nil {
+		_ = c.NoContent(http.StatusInternalServerError)
+		return
+	}
+
+	_ = c.Blob(p.Status, ContentType, body)
+}
@@ -0,0 +1,112 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   domain.ErrorCode
+		detail string
+	}{
+		{"not found", domain.ErrNotFound, http.StatusNotFound, domain.CodeNotFound, domain.ErrNotFound.Message},
+		{"wrapped conflict", fmt.Errorf("storing: %w", domain.ErrConflict), http.StatusConflict, domain.CodeConflict, domain.ErrConflict.Message},
+		{"bad input", domain.ErrBadInput, http.StatusBadRequest, domain.CodeBadInput, domain.ErrBadInput.Message},
+		{"precondition", domain.ErrPreconditionFailed, http.StatusPreconditionFailed, domain.CodePreconditionFailed, domain.ErrPreconditionFailed.Message},
+		{"precondition required", domain.NewError(domain.CodePreconditionRequired, "send If-Match"), http.StatusPreconditionRequired, domain.CodePreconditionRequired, "send If-Match"},
+		{"internal keeps its cause private", domain.WrapError(domain.CodeInternal, "db is down", errors.New("dial tcp")), http.StatusInternalServerError, domain.CodeInternal, "internal server error"},
+		{"unknown error", errors.New("sql: no rows in result set"), http.StatusInternalServerError, domain.CodeInternal, "internal server error"},
+		{"echo error", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed, domain.CodeBadInput, "Method Not Allowed"},
+		{"echo not found", echo.ErrNotFound, http.StatusNotFound, domain.CodeNotFound, "Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.From(tt.err)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.detail, p.Detail)
+			assert.Equal(t, http.StatusText(tt.status), p.Title)
+			assert.Equal(t, tt.status, problem.StatusCode(tt.err))
+		})
+	}
+}
+
+func TestFromValidationErrors(t *testing.T) {
+	err := problem.NewValidator().Struct(&domain.Author{})
+	require.Error(t, err)
+
+	p := problem.From(err)
+	assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	assert.Equal(t, domain.CodeValidation, p.Code)
+	assert.Equal(t, []domain.FieldError{{Field: "name", Message: "is required"}}, p.Errors)
+}
+
+func TestValidatorUsesJSONNames(t *testing.T) {
+	var v struct {
+		UpdatedAt string `json:"updated_at,omitempty" validate:"required"`
+		Hidden    string `json:"-" validate:"required"`
+		Untagged  string `validate:"required"`
+	}
+
+	p := problem.From(problem.NewValidator().Struct(&v))
+	assert.Equal(t, []domain.FieldError{
+		{Field: "updated_at", Message: "is required"},
+		{Field: "Hidden", Message: "is required"},
+		{Field: "Untagged", Message: "is required"},
+	}, p.Errors)
+}
+
+func TestErrorsIs(t *testing.T) {
+	err := fmt.Errorf("get: %w", domain.WrapError(domain.CodeNotFound, "no author 7", errors.New("sql: no rows")))
+
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	assert.False(t, errors.Is(err, domain.ErrConflict))
+	assert.Equal(t, domain.CodeNotFound, domain.ErrorCodeOf(err))
+	assert.Equal(t, domain.CodeInternal, domain.ErrorCodeOf(errors.New("boom")))
+
+	var de *domain.Error
+	require.True(t, errors.As(err, &de))
+	assert.Equal(t, "no author 7", de.Message)
+}
+
+func TestHandler(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	rec := httptest.NewRecorder()
+
+	problem.Handler(domain.ErrNotFound, e.NewContext(req, rec))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get(echo.HeaderContentType))
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, "/articles/1", p.Instance)
+	assert.Equal(t, domain.CodeNotFound, p.Code)
+	assert.Equal(t, "about:blank", p.Type)
+}
+
+func TestHandlerHead(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodHead, "/articles/1", nil)
+	rec := httptest.NewRecorder()
+
+	problem.Handler(domain.ErrNotFound, e.NewContext(req, rec))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,193 @@
+// Package ratelimit throttles clients with token buckets. Clients are told
+// where they stand through the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers and get 429 with Retry-After once their bucket is
+// empty.
+package ratelimit
+
+import (
+	"github.com/angelRaynov/clean-architecture/auth"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/labstack/echo"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+	HeaderRetry     = "Retry-After"
+)
+
+// defaultRoute names the bucket shared by the routes without a limit of
+// their own, and authenticationRoute the bucket of requests presenting
+// credentials.
+const (
+	defaultRoute        = "*"
+	authenticationRoute = "authentication"
+)
+
+type policy struct {
+	limit Limit
+	// header is the RateLimit-Policy value, such as "60;w=12".
+	header string
+}
+
+func newPolicy(l config.Limit) policy {
+	rate := float64(l.Requests) / l.Period.Seconds()
+	window := int(math.Ceil(float64(l.Burst) / rate))
+
+	return policy{
+		limit:  Limit{Rate: rate, Burst: l.Burst},
+		header: strconv.Itoa(l.Burst) + ";w=" + strconv.Itoa(window),
+	}
+}
+
+// Limiter is the rate limiting middleware.
+type Limiter struct {
+	store        Store
+	def          policy
+	auth         policy
+	routes       map[string]policy
+	exempt       map[string]bool
+	forwardedFor bool
+}
+
+// New returns a limiter enforcing cfg with the buckets of store. Requests to
+// the exempt route patterns, such as health checks, are never limited.
+func New(cfg config.RateLimit, store Store, exempt ...string) *Limiter {
+	l := &Limiter{
+		store:  store,
+		def:    newPolicy(cfg.Limit),
+		auth:   newPolicy(cfg.Authentication),
+		routes: make(map[string]policy, len(cfg.Routes)),
+		exempt: make(map[string]bool, len(exempt)),
+
+		forwardedFor: cfg.TrustForwardedFor,
+	}
+
+	for _, route := range exempt {
+		l.exempt[route] = true
+	}
+
+	for route, limit := range cfg.Routes {
+		l.routes[route] = newPolicy(limit)
+	}
+
+	return l
+}
+
+// clientKey identifies the caller: the authenticated subject, which is the
+// user or the API key, or else the client IP address.
+func (l *Limiter) clientKey(c echo.Context) string {
+	if id, ok := domain.IdentityFromContext(c.Request().Context()); ok {
+		return "id:" + id.Subject
+	}
+
+	return l.ipKey(c)
+}
+
+func (l *Limiter) ipKey(c echo.Context) string {
+	req := c.Request()
+	if l.forwardedFor {
+		return "ip:" + c.RealIP()
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// Middleware runs before the authentication middlewares, keyed by client IP
+// address. Anonymous requests take their token here. Requests presenting a
+// token or an API key take one from the IP's authentication bucket before
+// their credentials are checked, and their route token in Authenticated.
+func (l *Limiter) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if l.exempt[c.Path()] {
+			return next(c)
+		}
+
+		if !presentsCredentials(c.Request()) {
+			return l.take(c, next, l.ipKey(c))
+		}
+
+		if err := l.takeToken(c, authenticationRoute+"|"+l.ipKey(c), l.auth); err != nil {
+			return err
+		}
+
+		return next(c)
+	}
+}
+
+// Authenticated runs after the authentication middlewares and takes the
+// route token of requests that presented credentials, keyed by the caller.
+func (l *Limiter) Authenticated(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if l.exempt[c.Path()] || !presentsCredentials(c.Request()) {
+			return next(c)
+		}
+
+		return l.take(c, next, l.clientKey(c))
+	}
+}
+
+func presentsCredentials(req *http.Request) bool {
+	return req.Header.Get(echo.HeaderAuthorization) != "" || req.Header.Get(auth.HeaderAPIKey) != ""
+}
+
+// take takes a token from the bucket of the route for client.
+func (l *Limiter) take(c echo.Context, next echo.HandlerFunc, client string) error {
+	req := c.Request()
+
+	route := req.Method + " " + c.Path()
+	p, ok := l.routes[route]
+	if !ok {
+		p, route = l.def, defaultRoute
+	}
+
+	if err := l.takeToken(c, route+"|"+client, p); err != nil {
+		return err
+	}
+
+	return next(c)
+}
+
+// takeToken takes a token from the bucket under key and sets the headers.
+// When the store fails the request is let through; an outage of a shared
+// store must not take the API down with it.
+func (l *Limiter) takeToken(c echo.Context, key string, p policy) error {
+	ctx := c.Request().Context()
+
+	res, err := l.store.Take(ctx, key, p.limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("rate limit store failed", "err", err)
+		return nil
+	}
+
+	header := c.Response().Header()
+	header.Set(HeaderLimit, strconv.Itoa(p.limit.Burst))
+	header.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+	header.Set(HeaderReset, ceilSeconds(res.Reset))
+	header.Set(HeaderPolicy, p.header)
+
+	if !res.Allowed {
+		header.Set(HeaderRetry, ceilSeconds(res.RetryAfter))
+		return domain.ErrRateLimited
+	}
+
+	return nil
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
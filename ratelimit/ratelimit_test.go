@@ -0,0 +1,139 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"github.com/angelRaynov/clean-architecture/auth"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/angelRaynov/clean-architecture/ratelimit"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer(l *ratelimit.Limiter) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = problem.Handler
+	e.Use(l.Middleware)
+	// Bearer tokens name the subject; "bad" does not verify.
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			switch sub := strings.TrimPrefix(header, "Bearer "); sub {
+			case "":
+			case "bad":
+				return domain.ErrUnauthorized
+			default:
+				ctx := domain.WithIdentity(c.Request().Context(), domain.Identity{Subject: sub})
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	})
+	e.Use(l.Authenticated)
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/articles", ok)
+	e.GET("/articles/:id", ok)
+	e.GET("/healthz", ok)
+
+	return e
+}
+
+func get(e *echo.Echo, target, ip, subject string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = ip + ":1234"
+	if subject != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+subject)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestLimiter(t *testing.T) {
+	cfg := config.RateLimit{
+		Enabled:        true,
+		Limit:          config.Limit{Requests: 60, Period: time.Minute, Burst: 3},
+		Authentication: config.Limit{Requests: 60, Period: time.Minute, Burst: 60},
+		Routes: map[string]config.Limit{
+			"GET /articles": {Requests: 1, Period: time.Minute, Burst: 1},
+		},
+	}
+	e := newServer(ratelimit.New(cfg, ratelimit.NewMemoryStore(), "/healthz"))
+
+	rec := get(e, "/articles", "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(ratelimit.HeaderLimit))
+	assert.Equal(t, "0", rec.Header().Get(ratelimit.HeaderRemaining))
+	assert.Equal(t, "60", rec.Header().Get(ratelimit.HeaderReset))
+	assert.Equal(t, "1;w=60", rec.Header().Get(ratelimit.HeaderPolicy))
+
+	rec = get(e, "/articles", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get(ratelimit.HeaderRetry))
+	assert.Equal(t, problem.ContentType, rec.Header().Get(echo.HeaderContentType))
+
+	assert.Equal(t, http.StatusOK, get(e, "/articles", "10.0.0.2", "").Code, "each IP has its own bucket")
+	assert.Equal(t, http.StatusOK, get(e, "/articles", "10.0.0.1", "alice").Code, "users are keyed by subject")
+	assert.Equal(t, http.StatusTooManyRequests, get(e, "/articles", "10.0.0.3", "alice").Code)
+
+	for i := 0; i < 3; i++ {
+		rec = get(e, "/articles/1", "10.0.0.1", "")
+		assert.Equal(t, http.StatusOK, rec.Code, "other routes share the default bucket")
+	}
+	assert.Equal(t, "3", rec.Header().Get(ratelimit.HeaderLimit))
+	assert.Equal(t, http.StatusTooManyRequests, get(e, "/articles/2", "10.0.0.1", "").Code)
+
+	for i := 0; i < 5; i++ {
+		rec = get(e, "/healthz", "10.0.0.1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(ratelimit.HeaderLimit))
+	}
+}
+
+func TestLimiterLimitsCredentialsBeforeTheyAreChecked(t *testing.T) {
+	cfg := config.RateLimit{
+		Enabled:        true,
+		Limit:          config.Limit{Requests: 60, Period: time.Minute, Burst: 10},
+		Authentication: config.Limit{Requests: 1, Period: time.Minute, Burst: 2},
+	}
+	e := newServer(ratelimit.New(cfg, ratelimit.NewMemoryStore()))
+
+	assert.Equal(t, http.StatusUnauthorized, get(e, "/articles", "10.0.0.1", "bad").Code)
+	assert.Equal(t, http.StatusUnauthorized, get(e, "/articles", "10.0.0.1", "bad").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(e, "/articles", "10.0.0.1", "bad").Code, "guesses from one IP are limited")
+	assert.Equal(t, http.StatusTooManyRequests, get(e, "/articles", "10.0.0.1", "alice").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(auth.HeaderAPIKey, "guess")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "API keys count as credentials")
+
+	assert.Equal(t, http.StatusOK, get(e, "/articles", "10.0.0.1", "").Code, "anonymous requests have their own bucket")
+	assert.Equal(t, http.StatusOK, get(e, "/articles", "10.0.0.2", "alice").Code)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestLimiterFailsOpen(t *testing.T) {
+	cfg := config.RateLimit{Enabled: true, Limit: config.Limit{Requests: 1, Period: time.Minute, Burst: 1}}
+	e := newServer(ratelimit.New(cfg, failingStore{}))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, get(e, "/articles", "10.0.0.1", "").Code)
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 1, Burst: 3}
+	ctx := context.TODO()
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.Take(ctx, "a", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, err := s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	res, err = s.Take(ctx, "b", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "buckets are per key")
+
+	now = now.Add(1500 * time.Millisecond)
+	res, err = s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2500*time.Millisecond, res.Reset)
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	_, err := s.Take(context.TODO(), "slow", Limit{Rate: 0.001, Burst: 1})
+	require.NoError(t, err)
+	_, err = s.Take(context.TODO(), "fast", Limit{Rate: 10, Burst: 1})
+	require.NoError(t, err)
+
+	now = now.Add(2 * sweepEvery)
+	_, err = s.Take(context.TODO(), "other", Limit{Rate: 10, Burst: 1})
+	require.NoError(t, err)
+
+	assert.Contains(t, s.buckets, "slow")
+	assert.NotContains(t, s.buckets, "fast")
+}
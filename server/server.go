@@ -0,0 +1,311 @@
+// Package server wires the repositories, use cases and HTTP handlers together
+// and runs them as one service.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	keyDelivery "github.com/angelRaynov/clean-architecture/apikey/delivery/http"
+	keyRepo "github.com/angelRaynov/clean-architecture/apikey/repository/db"
+	keyMemory "github.com/angelRaynov/clean-architecture/apikey/repository/memory"
+	keyUsecase "github.com/angelRaynov/clean-architecture/apikey/usecase"
+	artDelivery "github.com/angelRaynov/clean-architecture/article/delivery/http"
+	artMIddleware "github.com/angelRaynov/clean-architecture/article/delivery/http/middleware"
+	artRepo "github.com/angelRaynov/clean-architecture/article/repository/db"
+	artMemory "github.com/angelRaynov/clean-architecture/article/repository/memory"
+	artUsecase "github.com/angelRaynov/clean-architecture/article/usecase"
+	"github.com/angelRaynov/clean-architecture/auth"
+	authDelivery "github.com/angelRaynov/clean-architecture/author/delivery/http"
+	authRepo "github.com/angelRaynov/clean-architecture/author/repository/db"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	authUsecase "github.com/angelRaynov/clean-architecture/author/usecase"
+	"github.com/angelRaynov/clean-architecture/cache"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/cursor"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/health"
+	"github.com/angelRaynov/clean-architecture/logging"
+	"github.com/angelRaynov/clean-architecture/metrics"
+	"github.com/angelRaynov/clean-architecture/problem"
+	"github.com/angelRaynov/clean-architecture/ratelimit"
+	"github.com/angelRaynov/clean-architecture/tracing"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds each readiness checker.
+const healthCheckTimeout = 2 * time.Second
+
+// App is the whole service: an echo server in front of the article and
+// author use cases, and the database they share.
+type App struct {
+	Echo *echo.Echo
+	// Health serves /healthz and /readyz; subsystems add their own checkers.
+	Health *health.Health
+	// Metrics serves /metrics; subsystems may register their own collectors.
+	Metrics *metrics.Metrics
+	// Logger writes JSON lines to stdout. Requests log through a copy tagged
+	// with their request ID, see logging.FromContext.
+	Logger *slog.Logger
+
+	cfg config.Config
+	db  *sql.DB
+
+	// requests is the parent of every request context; cancelling it aborts
+	// the use cases still running when the drain deadline passes.
+	requests       context.Context
+	cancelRequests context.CancelFunc
+
+	stopTracing func(context.Context) error
+
+	ready    chan struct{}
+	listener net.Listener
+	runOnce  sync.Once
+}
+
+// New connects to the configured storage and registers every handler. The
+// returned App owns the database connection; Run closes it on exit.
+func New(cfg config.Config) (*App, error) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = problem.Handler
+
+	level, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &App{
+		Echo:    e,
+		Logger:  logging.New(os.Stdout, level),
+		Health:  health.New(healthCheckTimeout),
+		Metrics: metrics.New(),
+		cfg:     cfg,
+		ready:   make(chan struct{}),
+	}
+
+	a.stopTracing, err = tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	middleware := artMIddleware.InitMiddleware(a.Logger, cfg.CORS)
+	e.Use(tracing.Middleware)
+	e.Use(a.Metrics.Middleware)
+	e.Use(middleware.RequestID)
+	e.Use(middleware.CORS)
+
+	// The limiter runs before authentication as well as after it, so
+	// guessing tokens and API keys costs tokens too.
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.New(cfg.RateLimit, ratelimit.NewMemoryStore(), "/healthz", "/readyz", "/metrics")
+		e.Use(limiter.Middleware)
+	}
+
+	if cfg.Auth.Enabled() {
+		authenticator, err := auth.New(cfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+		e.Use(authenticator.Middleware)
+	} else {
+		a.Logger.Warn("no token verification key is configured, only API keys can authenticate")
+	}
+
+	health.NewHandler(e, a.Health)
+	e.GET("/metrics", a.Metrics.Handler())
+	a.requests, a.cancelRequests = context.WithCancel(context.Background())
+
+	var authorRepo domain.AuthorRepository
+	var articleRepo domain.ArticleRepository
+	var apiKeyRepo domain.APIKeyRepository
+
+	if cfg.Database.InMemory() {
+		a.Logger.Warn("using in-memory storage, data is lost on restart")
+		authorRepo = authMemory.NewAuthorRepository()
+		articleRepo = artMemory.NewArticleRepository()
+		apiKeyRepo = keyMemory.NewAPIKeyRepository()
+	} else {
+		conn, dbDialect, err := OpenDatabase(cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+		a.db = conn
+		a.Logger.Info("connected to the database", "driver", dbDialect.DriverName())
+		a.Health.Register("database", health.DB(conn))
+		a.Metrics.RegisterDB(cfg.Database.Name, conn)
+
+		authorRepo = authRepo.NewAuthorRepositoryWithDialect(conn, dbDialect)
+		articleRepo = artRepo.NewArticleRepositoryWithDialect(conn, dbDialect)
+		apiKeyRepo = keyRepo.NewAPIKeyRepository(conn, dbDialect)
+	}
+
+	articleRepo = a.Metrics.ArticleRepository(articleRepo)
+	if cfg.Cache.Enabled {
+		store := cache.NewLRU(cfg.Cache.Size)
+		authorRepo = cache.AuthorRepository(authorRepo, store, cfg.Cache.TTL)
+		articleRepo = cache.ArticleRepository(articleRepo, store, cfg.Cache.TTL)
+	}
+
+	apiKeyUsecase := keyUsecase.NewAPIKeyUseCase(apiKeyRepo, authorRepo, cfg.ContextTimeout)
+	e.Use(auth.APIKeyMiddleware(apiKeyUsecase))
+	if limiter != nil {
+		e.Use(limiter.Authenticated)
+	}
+	keyDelivery.NewAPIKeyHandler(e, apiKeyUsecase)
+
+	pageSize := domain.PageSize{
+		Default: int64(cfg.Paging.DefaultPageSize),
+		Max:     int64(cfg.Paging.MaxPageSize),
+	}
+
+	articleUsecase := a.Metrics.ArticleUseCase(artUsecase.NewArticleUseCaseWithPaging(articleRepo, authorRepo, cfg.ContextTimeout, pageSize, cfg.Paging.CountCacheTTL))
+	articleUsecase = tracing.ArticleUseCase(articleUsecase)
+	if cfg.Paging.CursorSecret == "" {
+		a.Logger.Warn("page cursors are signed with a random secret and stop working on restart")
+	}
+	cursors := cursor.NewCodec([]byte(cfg.Paging.CursorSecret.Value()))
+	artDelivery.NewArticleHandlerWithPaging(e, articleUsecase, pageSize, cursors)
+
+	authorUsecase := authUsecase.NewAuthorUseCaseWithPageSize(authorRepo, cfg.ContextTimeout, pageSize)
+	authDelivery.NewAuthorHandlerWithPaging(e, authorUsecase, pageSize, cursors)
+
+	return a, nil
+}
+
+// OpenDatabase connects to the configured database and checks it answers.
+func OpenDatabase(cfg config.Database) (*sql.DB, dialect.Dialect, error) {
+	dbDialect, err := cfg.Dialect()
+	if err != nil {
+		return nil, "", err
+	}
+
+	dsn, err := cfg.DSN()
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := tracing.OpenDB(dbDialect, dsn)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = conn.Ping()
+	if err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+
+	return conn, dbDialect, nil
+}
+
+// Run serves HTTP until ctx is done, then shuts down: readiness fails at once,
+// requests are still served for Server.ReadinessDrainDelay while load
+// balancers notice, then new connections are refused, in-flight requests get Server.ShutdownTimeout to finish, whatever
+// is left is cancelled and the database is closed. Run returns nil after a
+// clean shutdown. An App can only be run once.
+func (a *App) Run(ctx context.Context) error {
+	err := errors.New("the app has already been run")
+	a.runOnce.Do(func() {
+		err = a.run(ctx)
+	})
+
+	return err
+}
+
+func (a *App) run(ctx context.Context) (err error) {
+	defer a.stopTelemetry(&err)
+	defer a.closeDatabase(&err)
+	defer a.cancelRequests()
+
+	a.listener, err = net.Listen("tcp", a.cfg.Server.Address)
+	close(a.ready)
+	if err != nil {
+		return err
+	}
+	a.Echo.Listener = a.listener
+	a.Echo.Server.BaseContext = func(net.Listener) context.Context { return a.requests }
+	a.Logger.Info("listening", "address", a.listener.Addr().String())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- a.Echo.StartServer(a.Echo.Server)
+	}()
+
+	select {
+	case err = <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	a.Health.ShuttingDown()
+	if delay := a.cfg.Server.ReadinessDrainDelay; delay > 0 {
+		a.Logger.Info("shutting down, waiting for load balancers to stop routing", "delay", delay)
+		select {
+		case err = <-served:
+			return err
+		case <-time.After(delay):
+		}
+	}
+
+	a.Logger.Info("shutting down, draining requests", "deadline", a.cfg.Server.ShutdownTimeout)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	err = a.Echo.Shutdown(drainCtx)
+	if err != nil {
+		a.Logger.Warn("drain deadline passed, cancelling in-flight requests", "err", err)
+		a.cancelRequests()
+		err = a.Echo.Close()
+	}
+
+	if serveErr := <-served; serveErr != nil && serveErr != http.ErrServerClosed && err == nil {
+		err = serveErr
+	}
+
+	return err
+}
+
+func (a *App) closeDatabase(err *error) {
+	if a.db == nil {
+		return
+	}
+
+	if closeErr := a.db.Close(); closeErr != nil && *err == nil {
+		*err = closeErr
+	}
+}
+
+// stopTelemetry flushes the spans still buffered by the exporter.
+func (a *App) stopTelemetry(err *error) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if stopErr := a.stopTracing(ctx); stopErr != nil && *err == nil {
+		*err = stopErr
+	}
+}
+
+// Addr waits until Run is listening and returns the address it listens on,
+// which is how tests find the port picked for ":0". It is nil when Run could
+// not listen.
+func (a *App) Addr() net.Addr {
+	<-a.ready
+	if a.listener == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
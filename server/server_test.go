@@ -0,0 +1,173 @@
+package server_test
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/auth"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/server"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const hmacSecret = "0123456789abcdef0123456789abcdef"
+
+func newApp(t *testing.T, shutdownTimeout time.Duration) *server.App {
+	return newAppWithDrainDelay(t, shutdownTimeout, 0)
+}
+
+func newAppWithDrainDelay(t *testing.T, shutdownTimeout, drainDelay time.Duration) *server.App {
+	cfg := config.Default()
+	cfg.Server.Address = "127.0.0.1:0"
+	cfg.Server.ShutdownTimeout = shutdownTimeout
+	cfg.Server.ReadinessDrainDelay = drainDelay
+	cfg.Database.Driver = config.MemoryDriver
+	cfg.Auth.HMACSecret = hmacSecret
+
+	app, err := server.New(cfg)
+	require.NoError(t, err)
+
+	return app
+}
+
+func start(app *server.App) (stop context.CancelFunc, done <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- app.Run(ctx)
+	}()
+
+	return cancel, errs
+}
+
+func TestAppServesUntilCancelled(t *testing.T) {
+	app := newApp(t, time.Second)
+	stop, done := start(app)
+	url := "http://" + app.Addr().String()
+
+	claims := auth.Claims{Roles: []string{"admin"}}
+	claims.Subject = "admin"
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(hmacSecret))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, url+"/authors", strings.NewReader(`{"name":"Ursula K. Le Guin"}`))
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res, err = http.Get(url + "/authors/1")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(url + "/readyz")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	stop()
+	require.NoError(t, <-done)
+
+	_, ready := app.Health.Ready(context.Background())
+	assert.False(t, ready, "readiness fails once shutdown starts")
+
+	_, err = http.Get(url + "/authors/1")
+	assert.Error(t, err, "the listener is closed after shutdown")
+
+	assert.Error(t, app.Run(context.Background()), "an app runs once")
+}
+
+func TestAppDrainsInFlightRequests(t *testing.T) {
+	app := newApp(t, time.Second)
+	started := make(chan struct{})
+	app.Echo.GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	stop, done := start(app)
+
+	status := make(chan int, 1)
+	go func() {
+		res, err := http.Get("http://" + app.Addr().String() + "/slow")
+		if err != nil {
+			status <- 0
+			return
+		}
+		res.Body.Close()
+		status <- res.StatusCode
+	}()
+
+	<-started
+	stop()
+
+	assert.Equal(t, http.StatusOK, <-status)
+	require.NoError(t, <-done)
+}
+
+func TestAppCancelsRequestsAfterDrainDeadline(t *testing.T) {
+	app := newApp(t, 50*time.Millisecond)
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	app.Echo.GET("/stuck", func(c echo.Context) error {
+		close(started)
+		<-c.Request().Context().Done()
+		cancelled <- c.Request().Context().Err()
+		return nil
+	})
+
+	stop, done := start(app)
+
+	go func() {
+		res, err := http.Get("http://" + app.Addr().String() + "/stuck")
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	<-started
+	stop()
+
+	select {
+	case err := <-cancelled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the in-flight request was never cancelled")
+	}
+	require.NoError(t, <-done)
+}
+
+func TestAppServesDuringReadinessDrainDelay(t *testing.T) {
+	app := newAppWithDrainDelay(t, time.Second, 300*time.Millisecond)
+	stop, done := start(app)
+	url := "http://" + app.Addr().String()
+
+	stop()
+	require.Eventually(t, func() bool {
+		_, ready := app.Health.Ready(context.Background())
+		return !ready
+	}, time.Second, 5*time.Millisecond)
+
+	res, err := http.Get(url + "/readyz")
+	require.NoError(t, err, "the listener stays open during the delay")
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	res, err = http.Get(url + "/articles")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.NoError(t, <-done)
+}
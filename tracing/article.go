@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// articleUseCase starts a span around every call to the wrapped
+// domain.ArticleUseCase.
+type articleUseCase struct {
+	next domain.ArticleUseCase
+}
+
+// ArticleUseCase wraps next so each method call gets its own span.
+func ArticleUseCase(next domain.ArticleUseCase) domain.ArticleUseCase {
+	return &articleUseCase{next: next}
+}
+
+func start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end is deferred with a pointer to the named error result. The errors a
+// client causes, such as domain.ErrNotFound, are recorded on the span without
+// marking it failed.
+func end(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		if domain.ErrorCodeOf(*err) == domain.CodeInternal {
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+	}
+
+	span.End()
+}
+
+func (a *articleUseCase) Fetch(ctx context.Context, filter domain.ArticleFilter, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	ctx, span := start(ctx, "ArticleUseCase.Fetch", pageAttributes(page)...)
+	defer end(span, &err)
+	return a.next.Fetch(ctx, filter, page)
+}
+
+func (a *articleUseCase) FetchByAuthor(ctx context.Context, authorID int64, page domain.PageRequest) (res []domain.Article, info domain.PageInfo, err error) {
+	ctx, span := start(ctx, "ArticleUseCase.FetchByAuthor", append(pageAttributes(page), attribute.Int64("author.id", authorID))...)
+	defer end(span, &err)
+	return a.next.FetchByAuthor(ctx, authorID, page)
+}
+
+func (a *articleUseCase) FetchPage(ctx context.Context, filter domain.ArticleFilter, page domain.OffsetRequest) (res []domain.Article, total int64, err error) {
+	ctx, span := start(ctx, "ArticleUseCase.FetchPage",
+		attribute.Int64("page", page.Page),
+		attribute.Int64("per_page", page.PerPage),
+		attribute.String("page.sort", string(page.Sort.Normalize().Field)),
+		attribute.Bool("page.desc", page.Sort.Desc),
+	)
+	defer end(span, &err)
+	return a.next.FetchPage(ctx, filter, page)
+}
+
+// pageAttributes records the size and order of a page and the article it
+// starts or ends next to.
+func pageAttributes(page domain.PageRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int64("num", page.Num),
+		attribute.String("page.sort", string(page.Sort.Normalize().Field)),
+		attribute.Bool("page.desc", page.Sort.Desc),
+	}
+	if page.After != nil {
+		attrs = append(attrs, attribute.Int64("page.after.id", page.After.ID))
+	}
+	if page.Before != nil {
+		attrs = append(attrs, attribute.Int64("page.before.id", page.Before.ID))
+	}
+
+	return attrs
+}
+
+func (a *articleUseCase) GetByID(ctx context.Context, id int64) (res domain.Article, err error) {
+	ctx, span := start(ctx, "ArticleUseCase.GetByID", attribute.Int64("article.id", id))
+	defer end(span, &err)
+	return a.next.GetByID(ctx, id)
+}
+
+func (a *articleUseCase) Update(ctx context.Context, ar *domain.Article, version time.Time) (err error) {
+	ctx, span := start(ctx, "ArticleUseCase.Update", attribute.Int64("article.id", ar.ID))
+	defer end(span, &err)
+	return a.next.Update(ctx, ar, version)
+}
+
+func (a *articleUseCase) GetByTitle(ctx context.Context, title string) (res domain.Article, err error) {
+	ctx, span := start(ctx, "ArticleUseCase.GetByTitle")
+	defer end(span, &err)
+	return a.next.GetByTitle(ctx, title)
+}
+
+func (a *articleUseCase) Search(ctx context.Context, query string, after *domain.SearchPosition, num int64) (res []domain.ArticleSearchResult, info domain.PageInfo, err error) {
+	attrs := []attribute.KeyValue{attribute.Int64("num", num)}
+	if after != nil {
+		attrs = append(attrs, attribute.Int64("page.after.id", after.ID))
+	}
+
+	ctx, span := start(ctx, "ArticleUseCase.Search", attrs...)
+	defer end(span, &err)
+	return a.next.Search(ctx, query, after, num)
+}
+
+func (a *articleUseCase) Store(ctx context.Context, ar *domain.Article) (err error) {
+	ctx, span := start(ctx, "ArticleUseCase.Store")
+	defer end(span, &err)
+	return a.next.Store(ctx, ar)
+}
+
+func (a *articleUseCase) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := start(ctx, "ArticleUseCase.Delete", attribute.Int64("article.id", id))
+	defer end(span, &err)
+	return a.next.Delete(ctx, id)
+}
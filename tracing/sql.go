@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"database/sql"
+	"github.com/XSAM/otelsql"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// OpenDB opens a database whose statements each get a span carrying the SQL
+// text. Connection bookkeeping and row iteration are left out to keep traces
+// readable.
+func OpenDB(d dialect.Dialect, dsn string) (*sql.DB, error) {
+	system := semconv.DBSystemMySQL
+	switch d {
+	case dialect.PostgreSQL:
+		system = semconv.DBSystemPostgreSQL
+	case dialect.SQLite:
+		system = semconv.DBSystemSqlite
+	}
+
+	return otelsql.Open(d.DriverName(), dsn,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			OmitConnectorConnect: true,
+		}))
+}
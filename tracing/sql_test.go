@@ -0,0 +1,59 @@
+//go:build cgo
+
+package tracing_test
+
+import (
+	"context"
+	artRepo "github.com/angelRaynov/clean-architecture/article/repository/db"
+	"github.com/angelRaynov/clean-architecture/dialect"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/migration"
+	"github.com/angelRaynov/clean-architecture/tracing"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenDBTracesStatements(t *testing.T) {
+	exporter := record(t)
+
+	db, err := tracing.OpenDB(dialect.SQLite, dialect.SQLite.DSN("", "", "", "", filepath.Join(t.TempDir(), "trace.db")))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migration.NewMigrator(db, dialect.SQLite)
+	require.NoError(t, err)
+	_, err = m.Up(context.TODO())
+	require.NoError(t, err)
+	exporter.Reset()
+
+	ctx, span := otel.Tracer("test").Start(context.TODO(), "request")
+	repo := artRepo.NewArticleRepositoryWithDialect(db, dialect.SQLite)
+	ar := domain.Article{Title: "traced", Content: "content", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, repo.Store(ctx, &ar))
+
+	duplicate := ar
+	assert.Equal(t, domain.ErrConflict, repo.Store(ctx, &duplicate), "driver errors still reach the dialect")
+
+	_, err = repo.GetByID(ctx, ar.ID)
+	require.NoError(t, err)
+	span.End()
+
+	var statements int
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "request" {
+			continue
+		}
+		assert.Equal(t, span.SpanContext().TraceID(), s.SpanContext.TraceID(), s.Name)
+		for _, attr := range s.Attributes {
+			if attr.Key == "db.statement" {
+				statements++
+			}
+		}
+	}
+	assert.GreaterOrEqual(t, statements, 3)
+}
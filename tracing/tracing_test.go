@@ -0,0 +1,148 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	artMemory "github.com/angelRaynov/clean-architecture/article/repository/memory"
+	artUsecase "github.com/angelRaynov/clean-architecture/article/usecase"
+	authMemory "github.com/angelRaynov/clean-architecture/author/repository/memory"
+	"github.com/angelRaynov/clean-architecture/config"
+	"github.com/angelRaynov/clean-architecture/domain"
+	"github.com/angelRaynov/clean-architecture/domain/mocks"
+	"github.com/angelRaynov/clean-architecture/tracing"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// record installs a provider exporting into memory, which stands in for a
+// collector, and returns the exporter.
+func record(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := config.Default().Tracing
+	provider := tracing.NewProvider(cfg, sdktrace.WithSyncer(exporter))
+
+	_, err := tracing.Setup(context.TODO(), config.Tracing{Exporter: config.ExporterNone})
+	require.NoError(t, err)
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return exporter
+}
+
+func byName(spans tracetest.SpanStubs) map[string][]tracetest.SpanStub {
+	res := map[string][]tracetest.SpanStub{}
+	for _, s := range spans {
+		res[s.Name] = append(res[s.Name], s)
+	}
+
+	return res
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := record(t)
+
+	e := echo.New()
+	e.Use(tracing.Middleware)
+	e.GET("/articles/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(echo.GET, "/articles/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /articles/:id", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Contains(t, spans[0].Attributes, attribute.Int("http.status_code", http.StatusNoContent))
+}
+
+func TestArticleUseCase(t *testing.T) {
+	exporter := record(t)
+
+	mockUCase := new(mocks.ArticleUseCase)
+	mockUCase.On("GetByID", mock.Anything, int64(1)).Return(domain.Article{}, domain.ErrNotFound).Once()
+	mockUCase.On("Delete", mock.Anything, int64(1)).Return(errors.New("connection reset")).Once()
+
+	u := tracing.ArticleUseCase(mockUCase)
+	_, err := u.GetByID(context.TODO(), 1)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Error(t, u.Delete(context.TODO(), 1))
+
+	spans := byName(exporter.GetSpans())
+	require.Len(t, spans["ArticleUseCase.GetByID"], 1)
+	require.Len(t, spans["ArticleUseCase.Delete"], 1)
+	assert.Equal(t, codes.Unset, spans["ArticleUseCase.GetByID"][0].Status.Code, "a missing article is not a failure")
+	assert.Equal(t, codes.Error, spans["ArticleUseCase.Delete"][0].Status.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestFetchSpansShareTheRequestTrace(t *testing.T) {
+	exporter := record(t)
+	ctx := context.TODO()
+
+	authors := authMemory.NewAuthorRepository()
+	articles := artMemory.NewArticleRepository()
+	for i := 0; i < 2; i++ {
+		au := domain.Author{Name: "author"}
+		require.NoError(t, authors.Store(ctx, &au))
+		ar := domain.Article{Title: "title " + string(rune('a'+i)), Content: "content", Author: au, CreatedAt: time.Now()}
+		require.NoError(t, articles.Store(ctx, &ar))
+	}
+
+	u := tracing.ArticleUseCase(artUsecase.NewArticleUseCase(articles, authors, time.Second))
+	e := echo.New()
+	e.Use(tracing.Middleware)
+	e.GET("/articles", func(c echo.Context) error {
+		list, _, err := u.Fetch(c.Request().Context(), domain.ArticleFilter{}, domain.PageRequest{Num: 10})
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, list)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/articles", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	all := exporter.GetSpans()
+	spans := byName(all)
+	require.Len(t, spans["GET /articles"], 1)
+	require.Len(t, spans["ArticleUseCase.Fetch"], 1)
+	require.Len(t, spans["articleUseCase.fillAuthorDetails"], 1)
+	require.Len(t, spans["articleUseCase.fillAuthorDetails.GetAuthors"], 1, "both authors are loaded at once")
+
+	root := spans["GET /articles"][0]
+	for _, s := range all {
+		assert.Equal(t, root.SpanContext.TraceID(), s.SpanContext.TraceID(), s.Name)
+	}
+
+	fill := spans["articleUseCase.fillAuthorDetails"][0]
+	for _, s := range spans["articleUseCase.fillAuthorDetails.GetAuthors"] {
+		assert.Equal(t, fill.SpanContext.SpanID(), s.Parent.SpanID())
+	}
+}
+
+func TestSetup(t *testing.T) {
+	stop, err := tracing.Setup(context.TODO(), config.Tracing{Exporter: config.ExporterStdout, SampleRatio: 1, ServiceName: "test"})
+	require.NoError(t, err)
+	assert.NoError(t, stop(context.TODO()))
+
+	_, err = tracing.Setup(context.TODO(), config.Tracing{Exporter: "zipkin"})
+	assert.Error(t, err)
+}